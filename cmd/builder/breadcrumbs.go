@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// Crumb is one step in a breadcrumb trail.
+type Crumb struct {
+	Label   string
+	URL     string // empty when no page exists at that level yet
+	Current bool   // the page being rendered
+}
+
+// BreadcrumbsData is the data for the "breadcrumbs" section. The builder fills
+// Items from the page path, so pages just add the section with empty data.
+type BreadcrumbsData struct {
+	Items []Crumb
+}
+
+// breadcrumbTrail walks from the home page down to page, one step per
+// directory in its URL. Directories with a page of their own link to it and
+// use its nav label; the rest get a label made from the directory name, e.g.
+// "registrations" becomes "Registrations".
+func breadcrumbTrail(page Page, byURL map[string]Page) []Crumb {
+	trail := []Crumb{{Label: "Home", URL: "/"}}
+	if home, ok := byURL["/"]; ok {
+		trail[0].Label = home.NavLabel()
+	}
+
+	dirs := strings.Split(strings.Trim(page.URL(), "/"), "/")
+	if dirs[0] == "" {
+		trail[0].Current = true
+		return trail
+	}
+	for i, dir := range dirs {
+		url := "/" + path.Join(dirs[:i+1]...) + "/"
+		crumb := Crumb{Label: dirLabel(dir)}
+		if p, ok := byURL[url]; ok {
+			crumb.Label = p.NavLabel()
+			crumb.URL = url
+		}
+		if i == len(dirs)-1 {
+			crumb.Label = page.NavLabel()
+			crumb.URL = page.URL()
+			crumb.Current = true
+		}
+		trail = append(trail, crumb)
+	}
+	return trail
+}
+
+// withBreadcrumbs returns the page's sections with the trail filled into any
+// "breadcrumbs" section that doesn't set its own items.
+func withBreadcrumbs(sections []Section, trail []Crumb) []Section {
+	out := make([]Section, len(sections))
+	for i, s := range sections {
+		out[i] = s
+		if data, ok := s.Data.(BreadcrumbsData); ok && len(data.Items) == 0 {
+			out[i].Data = BreadcrumbsData{Items: trail}
+		}
+	}
+	return out
+}
+
+// dirLabel turns a URL segment like "new-company" into "New Company".
+func dirLabel(dir string) string {
+	words := strings.Split(dir, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
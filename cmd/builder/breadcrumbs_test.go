@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// trailString writes a trail compactly: "Label /url/" per step, a "*" after
+// the current one.
+func trailString(trail []Crumb) string {
+	var parts []string
+	for _, c := range trail {
+		s := strings.TrimSpace(c.Label + " " + c.URL)
+		if c.Current {
+			s += "*"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " > ")
+}
+
+func TestBreadcrumbTrail(t *testing.T) {
+	pages := []Page{
+		{Path: "index.html", Title: "SA Tax Returns", Nav: NavOptions{Label: "Start"}},
+		{Path: "registrations/index.html", Title: "Registrations | SA Tax Returns"},
+		{Path: "registrations/vat/index.html", Title: "VAT Registration | SA Tax Returns"},
+		{Path: "submissions/personal-tax/index.html", Title: "Personal Tax | SA Tax Returns"},
+		{Path: "submissions/personal-tax/thank-you/index.html", Title: "Thank You | SA Tax Returns"},
+	}
+	byURL := make(map[string]Page)
+	for _, p := range pages {
+		byURL[p.URL()] = p
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"index.html", "Start /*"},
+		{"registrations/vat/index.html", "Start / > Registrations /registrations/ > VAT Registration /registrations/vat/*"},
+		// No page at /submissions/: named after the directory, not linked
+		{"submissions/personal-tax/index.html", "Start / > Submissions > Personal Tax /submissions/personal-tax/*"},
+		{"submissions/personal-tax/thank-you/index.html", "Start / > Submissions > Personal Tax /submissions/personal-tax/ > Thank You /submissions/personal-tax/thank-you/*"},
+	}
+	for _, tt := range tests {
+		if got := trailString(breadcrumbTrail(byURL[Page{Path: tt.path}.URL()], byURL)); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBreadcrumbTrailWithoutHomePage(t *testing.T) {
+	page := Page{Path: "about/index.html", Title: "About Us"}
+	got := trailString(breadcrumbTrail(page, map[string]Page{page.URL(): page}))
+	if want := "Home / > About Us /about/*"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestWithBreadcrumbs(t *testing.T) {
+	trail := []Crumb{{Label: "Home", URL: "/"}, {Label: "About", URL: "/about/", Current: true}}
+	own := BreadcrumbsData{Items: []Crumb{{Label: "Custom"}}}
+	out := withBreadcrumbs([]Section{
+		{TemplateName: "hero", Data: HeroData{Title: "About"}},
+		{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+		{TemplateName: "breadcrumbs", Data: own},
+	}, trail)
+	if got := fmt.Sprint(out[1].Data); got != fmt.Sprint(BreadcrumbsData{Items: trail}) {
+		t.Errorf("empty section got %s, want the trail", got)
+	}
+	if got := fmt.Sprint(out[2].Data); got != fmt.Sprint(own) {
+		t.Errorf("section with its own items got %s", got)
+	}
+}
+
+func TestDirLabel(t *testing.T) {
+	for dir, want := range map[string]string{
+		"registrations":     "Registrations",
+		"new-company":       "New Company",
+		"vat--registration": "Vat  Registration",
+		"":                  "",
+	} {
+		if got := dirLabel(dir); got != want {
+			t.Errorf("dirLabel(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/directory"
+)
+
+// contentDir holds page files for copywriters: data files (YAML or JSON) and
+// Markdown pages with front matter.
+const contentDir = "content"
+
+// contentSources lists every place pages are loaded from, in build order.
+var contentSources = []struct {
+	Name string
+	Load func() ([]Page, error)
+}{
+	{"definitions.go", loadGoContent},
+	{contentDir, func() ([]Page, error) { return loadContentDir(contentDir) }},
+	{directory.Dir, loadPractitionerPages},
+}
+
+// loadPages collects the pages from every content source. Two pages with the
+// same Path would overwrite each other's output, so that fails the build.
+func loadPages() ([]Page, error) {
+	var pages []Page
+	seen := make(map[string]string) // output path -> source
+	for _, src := range contentSources {
+		p, err := src.Load()
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", src.Name, err)
+		}
+		for _, page := range p {
+			key := filepath.Clean(page.Path)
+			if prev, ok := seen[key]; ok {
+				return nil, fmt.Errorf("path collision: %s is defined by both %s and %s", page.Path, prev, page.source)
+			}
+			seen[key] = page.source
+		}
+		pages = append(pages, p...)
+	}
+	return pages, nil
+}
+
+func loadGoContent() ([]Page, error) {
+	pages := GetSiteContent()
+	for i := range pages {
+		pages[i].source = "definitions.go"
+	}
+	return pages, nil
+}
+
+// loadContentDir reads every page data file below dir. A missing directory
+// is not an error; it simply contributes no pages.
+func loadContentDir(dir string) ([]Page, error) {
+	var pages []Page
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		var page Page
+		switch {
+		case d.IsDir():
+			return nil
+		case isDataFile(path):
+			page, err = loadPageFile(path)
+		case strings.EqualFold(filepath.Ext(path), ".md"):
+			page, err = loadMarkdownFile(path)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	return pages, err
+}
+
+func isDataFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// loadPageFile decodes a single page. JSON is valid YAML, so both formats go
+// through the same decoder and use the same keys.
+func loadPageFile(path string) (Page, error) {
+	var page Page
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return page, err
+	}
+	if err := decodePage(raw, &page); err != nil {
+		return page, fmt.Errorf("%s: %w", path, err)
+	}
+	if page.Path == "" {
+		return page, fmt.Errorf("%s: missing path", path)
+	}
+	page.source = path
+	return page, nil
+}
+
+// decodePage decodes a page from a data file or front matter. Misspelled
+// keys would otherwise vanish without a trace, so the keys the page has no
+// field for are kept for validatePages to report; sections keep their own.
+func decodePage(raw []byte, page *Page) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	if err := node.Decode(page); err != nil {
+		return err
+	}
+	page.unknown = unknownKeys(&node, reflect.TypeOf(*page))
+	return nil
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	nodeType        = reflect.TypeOf(yaml.Node{})
+)
+
+// unknownKeys returns the mapping keys in node that t has no field for,
+// looking into nested structs, slices and maps the way yaml.v3 decodes them.
+// Types that decode themselves, like Section, check their own keys.
+func unknownKeys(node *yaml.Node, t reflect.Type) []*yaml.Node {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case node.Kind == yaml.DocumentNode && len(node.Content) > 0:
+		return unknownKeys(node.Content[0], t)
+	case node.Kind == yaml.AliasNode && node.Alias != nil:
+		return unknownKeys(node.Alias, t)
+	case t == nodeType || reflect.PointerTo(t).Implements(unmarshalerType):
+		return nil
+	}
+
+	var unknown []*yaml.Node
+	switch {
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				unknown = append(unknown, key)
+				continue
+			}
+			unknown = append(unknown, unknownKeys(value, field)...)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 1; i < len(node.Content); i += 2 {
+			unknown = append(unknown, unknownKeys(node.Content[i], t.Elem())...)
+		}
+	case node.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for _, item := range node.Content {
+			unknown = append(unknown, unknownKeys(item, t.Elem())...)
+		}
+	}
+	return unknown
+}
+
+// yamlFields maps the keys yaml.v3 decodes into struct t to their types:
+// the yaml tag name or the lowercased field name, with ",inline" structs
+// merged in.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for key, typ := range yamlFields(f.Type) {
+				fields[key] = typ
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// UnmarshalYAML decodes a section, picking the Data struct from TemplateName
+// so templates receive the same typed values as Go-defined pages.
+func (s *Section) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		TemplateName string    `yaml:"template"`
+		Data         yaml.Node `yaml:"data"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	spec, ok := sectionRegistry[raw.TemplateName]
+	if !ok {
+		return fmt.Errorf("line %d: unknown section template %q", value.Line, raw.TemplateName)
+	}
+
+	data := reflect.New(spec.Type)
+	if !raw.Data.IsZero() {
+		if err := raw.Data.Decode(data.Interface()); err != nil {
+			return fmt.Errorf("line %d: %s data: %w", value.Line, raw.TemplateName, err)
+		}
+	}
+
+	s.TemplateName = raw.TemplateName
+	s.Data = data.Elem().Interface()
+	s.unknown = append(unknownKeys(value, reflect.TypeOf(raw)), unknownKeys(&raw.Data, spec.Type)...)
+	return nil
+}
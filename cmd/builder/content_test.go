@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyLines formats unknown keys as "line:key".
+func keyLines(keys []*yaml.Node) []string {
+	out := []string{}
+	for _, key := range keys {
+		out = append(out, fmt.Sprintf("%d:%s", key.Line, key.Value))
+	}
+	return out
+}
+
+// pageUnknownKeys returns the unknown keys of page and its sections.
+func pageUnknownKeys(page Page) []string {
+	keys := keyLines(page.unknown)
+	for _, s := range page.Sections {
+		keys = append(keys, keyLines(s.unknown)...)
+	}
+	return keys
+}
+
+func TestDecodePageUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "all known",
+			yaml: `
+title: About
+path: about/index.html
+terms:
+  service: [VAT Registration]
+nav:
+  label: About
+sections:
+  - template: hero
+    data:
+      title: About Us`,
+			want: []string{},
+		},
+		{
+			name: "top-level typo",
+			yaml: `
+titel: About
+path: about/index.html`,
+			want: []string{"2:titel"},
+		},
+		{
+			name: "nested struct typo",
+			yaml: `
+path: about/index.html
+nav:
+  hidden: true
+sitemap:
+  priorty: 0.5`,
+			want: []string{"4:hidden", "6:priorty"},
+		},
+		{
+			name: "section key typo",
+			yaml: `
+path: about/index.html
+sections:
+  - template: hero
+    dat:
+      title: About`,
+			want: []string{"5:dat"},
+		},
+		{
+			name: "section data typo",
+			yaml: `
+path: about/index.html
+sections:
+  - template: features
+    data:
+      title: Why Us
+      items:
+        - name: Fast
+          descripton: Same day`,
+			want: []string{"9:descripton"},
+		},
+		{
+			name: "inline struct",
+			yaml: `
+path: contact/index.html
+sections:
+  - template: contact_form
+    data:
+      title: Write to us
+      button_text: Send
+      buton: Send`,
+			want: []string{"8:buton"},
+		},
+		{
+			name: "alias",
+			yaml: `
+path: about/index.html
+sections:
+  - template: hero
+    data: &hero
+      title: About
+      subtitel: Who we are
+  - template: hero
+    data: *hero`,
+			want: []string{"7:subtitel", "7:subtitel"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var page Page
+			if err := decodePage([]byte(tt.yaml), &page); err != nil {
+				t.Fatal(err)
+			}
+			if got := pageUnknownKeys(page); !slices.Equal(got, tt.want) {
+				t.Errorf("unknown keys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePageUnknownTemplate(t *testing.T) {
+	var page Page
+	err := decodePage([]byte("path: a/index.html\nsections:\n  - template: heor\n"), &page)
+	if err == nil || !strings.Contains(err.Error(), `unknown section template "heor"`) {
+		t.Errorf("error = %v, want the unknown template", err)
+	}
+}
+
+func TestUnknownKeysFollowsYAMLNaming(t *testing.T) {
+	type inner struct {
+		Depth int
+	}
+	var v struct {
+		Name   string           `yaml:"name"`
+		Secret string           `yaml:"-"`
+		Plain  int              // no tag: the lowercased name
+		Inner  inner            `yaml:",inline"`
+		Nested map[string]inner `yaml:"nested"`
+	}
+	var node yaml.Node
+	src := "name: a\nsecret: b\nplain: 1\ndepth: 2\nnested:\n  x:\n    depth: 3\n    width: 4\n"
+	if err := yaml.Unmarshal([]byte(src), &node); err != nil {
+		t.Fatal(err)
+	}
+	got := keyLines(unknownKeys(&node, reflect.TypeOf(v)))
+	if want := []string{"2:secret", "8:width"}; !slices.Equal(got, want) {
+		t.Errorf("unknown keys = %q, want %q", got, want)
+	}
+}
+
+func TestValidateSection(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`{{ define "hero" }}{{ end }}{{ define "features" }}{{ end }}`))
+	tests := []struct {
+		name    string
+		section Section
+		want    []string
+	}{
+		{"valid", Section{TemplateName: "hero", Data: HeroData{Title: "Hi"}}, nil},
+		{"unregistered", Section{TemplateName: "carousel", Data: HeroData{}}, []string{"unknown section template"}},
+		{"no template", Section{TemplateName: "text_block", Data: TextBlockData{Heading: "H", Paragraphs: []string{"p"}}}, []string{"no template defined in components/sections/"}},
+		{"wrong data", Section{TemplateName: "hero", Data: TextBlockData{}}, []string{"data is main.TextBlockData, want main.HeroData"}},
+		{"missing string", Section{TemplateName: "hero", Data: HeroData{}}, []string{"missing required field Title"}},
+		{"empty slice", Section{TemplateName: "features", Data: FeaturesData{Title: "T", Items: []FeatureItem{}}}, []string{"missing required field Items"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateSection(tt.section, tmpl); !slices.Equal(got, tt.want) {
+				t.Errorf("validateSection = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,68 @@
 package main
 
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/directory"
+	"website/internal/forms"
+)
+
 // Page represents a single page on the website.
 type Page struct {
-	Title       string
-	Description string
-	Path        string // e.g., "index.html" or "about/index.html"
-	Sections    []Section
+	ID          string    `yaml:"id"` // optional name for {{ url "<id>" }}, defaults to the directory
+	Title       string    `yaml:"title"`
+	Description string    `yaml:"description"`
+	Path        string    `yaml:"path"`   // e.g., "index.html" or "about/index.html"
+	Layout      string    `yaml:"layout"` // template in components/layouts/, defaults to "base.html"
+	Sections    []Section `yaml:"sections"`
+
+	// Terms tags the page for the taxonomy pages (taxonomy.go), e.g.
+	// {"service": {"VAT Registration"}}.
+	Terms map[string][]string `yaml:"terms"`
+
+	Nav     NavOptions     `yaml:"nav"`
+	Social  SocialMeta     `yaml:"social"`
+	Sitemap SitemapOptions `yaml:"sitemap"`
+
+	source  string       // where the page was defined, for error messages
+	unknown []*yaml.Node // keys in a content file that Page has no field for
+}
+
+// URL is the page's site-relative address in trailing-slash form,
+// e.g. "about/index.html" becomes "/about/".
+func (p Page) URL() string {
+	rel := filepath.ToSlash(p.Path)
+	if path.Base(rel) == "index.html" {
+		rel = strings.TrimSuffix(rel, "index.html")
+	}
+	return "/" + rel
+}
+
+// SocialMeta overrides what link previews (OpenGraph, Twitter cards) show.
+// Empty fields fall back to the hero background image and the page title.
+type SocialMeta struct {
+	Image string `yaml:"image"` // site-relative or absolute URL
+	Title string `yaml:"title"`
+	Type  string `yaml:"type"` // OpenGraph type, defaults to "website"
 }
 
 // Section represents a reusable content block.
 // The TemplateName must match a defined template name (e.g., "hero", "features").
 // Data is passed to that template.
+// In content files a section is written as {template: <name>, data: {...}}.
 type Section struct {
 	TemplateName string
 	Data         interface{}
+
+	unknown []*yaml.Node // keys in a content file that Data has no field for
 }
 
-// SiteContent defines all the pages in the site.
+// GetSiteContent defines the pages that live in Go.
+// Pages can also be added as data files in content/ (see content.go).
 func GetSiteContent() []Page {
 	return []Page{
 		// 1. Home Page
@@ -24,6 +70,8 @@ func GetSiteContent() []Page {
 			Title:       "SA Tax Returns - Find an Accountant",
 			Description: "Connect with verified tax practitioners and accountants for your tax returns.",
 			Path:        "index.html",
+			Nav:         NavOptions{Label: "Home", Order: 1},
+			Sitemap:     SitemapOptions{Priority: 1.0, ChangeFreq: "weekly"},
 			Sections: []Section{
 				{
 					TemplateName: "hero",
@@ -31,7 +79,9 @@ func GetSiteContent() []Page {
 						Title:           "Professional Accountants & Consultants in Cape Town",
 						Subtitle:        "Are you looking for professional accountants and tax consultants in Cape Town? Stop stressing about SARS. We handle your Personal Tax, VAT, Payroll, and CIPC compliance so you can focus on growing your business.",
 						PrimaryBtn:      "Book Free Consultation",
+						PrimaryLink:     "/contact/",
 						SecondaryBtn:    "View Our Services",
+						SecondaryLink:   "/submissions/",
 						BackgroundImage: "/assets/images/hero_background_capetown.png", // Assuming image saved here
 					},
 				},
@@ -40,44 +90,20 @@ func GetSiteContent() []Page {
 					Data: FeaturesData{
 						Title: "How It Works",
 						Items: []FeatureItem{
-							{Name: "Search Professionals", Description: "Browse our directory of verified tax practitioners by expertise and location."},
-							{Name: "Compare Services", Description: "View profiles, services, and reviews to find the perfect match for your needs."},
-							{Name: "Get Listed", Description: "Are you an accountant? List your practice today to reach thousands of potential clients."},
+							{Name: "Search Professionals", Description: "Browse our directory of verified tax practitioners by expertise and location.", Link: directoryURL},
+							{Name: "Compare Services", Description: "View profiles, services, and reviews to find the perfect match for your needs.", Link: directoryURL},
+							{Name: "Get Listed", Description: "Are you an accountant? List your practice today to reach thousands of potential clients.", Link: getListedURL},
 						},
 					},
 				},
 			},
 		},
-		// 2. About Page
-		{
-			Title:       "About - SA Tax Returns",
-			Description: "Our mission to simplify tax filing in South Africa.",
-			Path:        "about/index.html",
-			Sections: []Section{
-				{
-					TemplateName: "hero",
-					Data: HeroData{
-						Title:    "Bridging the Gap",
-						Subtitle: "Connecting taxpayers with the right direct professional help.",
-					},
-				},
-				{
-					TemplateName: "text_block",
-					Data: TextBlockData{
-						Heading: "Our Mission",
-						Paragraphs: []string{
-							"Filing taxes can be daunting. SA Tax Returns was built to make professional tax assistance accessible to everyone. We believe that finding a qualified accountant should be as easy as searching for a restaurant.",
-							"We verify every practitioner on our platform to ensure you get high-quality advice and service. Whether you are an individual needing help with eFiling or a business looking for comprehensive bookkeeping, we have the right professional for you.",
-						},
-					},
-				},
-			},
-		},
-		// 3. Contact Page
+		// 2. Contact Page
 		{
 			Title:       "Contact Us",
 			Description: "Get in touch with the SA Tax Returns team.",
 			Path:        "contact/index.html",
+			Nav:         NavOptions{Label: "Contact", Order: 90},
 			Sections: []Section{
 				{
 					TemplateName: "hero",
@@ -88,20 +114,53 @@ func GetSiteContent() []Page {
 				},
 				{
 					TemplateName: "contact_form",
-					Data: ContactFormData{
+					Data: ContactFormData{FormData{
 						Title:      "Send us a Message",
 						ButtonText: "Submit Inquiry",
-					},
+					}},
 				},
 			},
 		},
+		// 3. Get Listed Page
+		{
+			Title:       "Get Listed | SA Tax Returns",
+			Description: "Are you a SAIT or SAICA registered tax practitioner? Apply for a free profile in the SA Tax Returns directory.",
+			Path:        "get-listed/index.html",
+			Nav:         NavOptions{Hide: true},
+			Sections: []Section{
+				{TemplateName: "hero", Data: HeroData{
+					Title:           "List Your Practice",
+					Subtitle:        "Reach taxpayers looking for a registered practitioner in their area. Listing is free for SAIT and SAICA members.",
+					PrimaryBtn:      "Apply Now",
+					BackgroundImage: directoryImage,
+				}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+				{TemplateName: "text_block", Data: TextBlockData{
+					Heading: "How Listing Works",
+					Paragraphs: []string{
+						"Tell us about your practice using the form below. We check your registration number with SAIT or SAICA before anything is published.",
+						"Once approved, your profile appears in the directory and on the service and area pages that match what you offer. Client enquiries from your profile go straight to your inbox.",
+					},
+				}},
+				{TemplateName: "form", Data: FormData{
+					Title:      "Apply to Be Listed",
+					Intro:      "Applications are usually reviewed within three working days.",
+					ButtonText: "Submit Application",
+					Kind:       forms.KindListing,
+					Fields:     directory.ApplicationFields(),
+				}},
+			},
+		},
 		// --- Submissions Pages ---
 		{
 			Title:       "Personal Tax Services - Expert Individual Tax Filing | SA Tax Returns",
 			Description: "Professional assistance with your Personal Income Tax (ITR12) submissions. Ensure full SARS compliance and maximize your refund with our registered tax practitioners.",
 			Path:        "submissions/personal-tax/index.html",
+			Terms:       map[string][]string{"service": {"Personal Tax Returns"}},
+			Nav:         NavOptions{Group: "Submissions", Label: "Personal Tax", Order: 21},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "Personal Tax Returns (ITR12)", Subtitle: "Simpify your personal tax filing season. We help salary earners, commission earners, and freelancers submit accurate returns on time.", PrimaryBtn: "File My Return"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Takes the Stress Out of Tax Season",
 					Paragraphs: []string{
@@ -109,14 +168,21 @@ func GetSiteContent() []Page {
 						"We review your auto-assessment, check for missing medical aid credits, verifying retirement annuity contributions, and ensure your home office expenses are valid before submission. Don't leave money on the table or risk an audit.",
 					},
 				}},
+				serviceForm("Get Help With Your Return", "Tell us about your tax affairs and a registered practitioner will get back to you.", "Send My Details",
+					forms.Field{Name: "tax_year", Label: "Tax year", Type: forms.TypeSelect, Required: true, Options: []string{"2026 (Mar 2025 - Feb 2026)", "2025 (Mar 2024 - Feb 2025)", "Earlier years"}},
+					forms.Field{Name: "income", Label: "Income types", Type: forms.TypeSelect, Options: []string{"Salary only", "Salary and other income", "Self-employed or freelance", "Rental or investment income"}},
+				),
 			},
 		},
 		{
 			Title:       "VAT Returns & Submissions services | SA Tax Returns",
 			Description: "Timely and accurate VAT201 submissions for South African businesses. We handle output/input tax calculations to keep you penalty-free.",
 			Path:        "submissions/vat/index.html",
+			Terms:       map[string][]string{"service": {"VAT Submissions"}},
+			Nav:         NavOptions{Group: "Submissions", Label: "Value Added Tax (VAT)", Order: 22},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "VAT Submissions (VAT201)", Subtitle: "Ensure your Value Added Tax returns are accurate and submitted on time, every billing period.", PrimaryBtn: "Get VAT Help"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Reliable VAT Compliance",
 					Paragraphs: []string{
@@ -124,14 +190,22 @@ func GetSiteContent() []Page {
 						"Our accountants review your invoices, verify input tax claims, and prepare your VAT201 return. We also handle the verification process if SARS audits a refund, preparing the necessary schedule of documents so you get your cash flow back sooner.",
 					},
 				}},
+				serviceForm("Get VAT Help", "Tell us about your VAT obligations and we'll be in touch.", "Send My Details",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "vat_period", Label: "VAT period", Type: forms.TypeSelect, Required: true, Options: []string{"Monthly", "Every two months", "Every four months", "Not sure"}},
+					forms.Field{Name: "behind", Label: "I have outstanding VAT returns", Type: forms.TypeCheckbox},
+				),
 			},
 		},
 		{
 			Title:       "Company Tax Return (ITR14) Services | SA Tax Returns",
 			Description: "Comprehensive Corporate Income Tax (CIT) filing for Pty Ltds and Close Corporations. Optimize your tax position with expert advice.",
 			Path:        "submissions/company-tax/index.html",
+			Terms:       map[string][]string{"service": {"Company Tax Returns"}},
+			Nav:         NavOptions{Group: "Submissions", Label: "Company Tax", Order: 23},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "Company Tax Returns (ITR14)", Subtitle: "Expert corporate tax compliance and planning for growing businesses.", PrimaryBtn: "Consult Now"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Corporate Tax Done Right",
 					Paragraphs: []string{
@@ -139,14 +213,21 @@ func GetSiteContent() []Page {
 						"We prepare your Annual Financial Statements (AFS) where required and ensure your ITR14 accurately reflects your financial position. We identify allowances like s12E (Small Business Corporation) validation to legally minimize your tax liability.",
 					},
 				}},
+				serviceForm("Request a Consultation", "Tell us about your company and a practitioner will contact you.", "Request Consultation",
+					forms.Field{Name: "company", Label: "Company name", Required: true},
+					forms.Field{Name: "turnover", Label: "Annual turnover", Type: forms.TypeSelect, Options: []string{"Under R1 million", "R1 - 10 million", "R10 - 50 million", "Over R50 million"}},
+				),
 			},
 		},
 		{
 			Title:       "PAYE & EMP201 Submissions | SA Tax Returns",
 			Description: "Monthly payroll tax submissions (PAYE, SDL, UIF) for employers. Avoid the 10% late payment penalty with our automated services.",
 			Path:        "submissions/paye/index.html",
+			Terms:       map[string][]string{"service": {"PAYE Returns"}},
+			Nav:         NavOptions{Group: "Submissions", Label: "PAYE Returns", Order: 24},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "PAYE Returns (EMP201)", Subtitle: "Hassle-free monthly payroll tax submissions for employers.", PrimaryBtn: "Manage My Payroll"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Simplified Payroll Compliance",
 					Paragraphs: []string{
@@ -154,6 +235,10 @@ func GetSiteContent() []Page {
 						"We manage your monthly EMP201 declarations, ensuring that all employee tax certificates (IRP5s) reconcile correctly at the end of the year (EMP501). Focus on your team, while we handle the tax authorities.",
 					},
 				}},
+				serviceForm("Get Payroll Help", "Tell us about your payroll and we'll be in touch.", "Send My Details",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "employees", Label: "Number of employees", Type: forms.TypeSelect, Required: true, Options: []string{"1 - 5", "6 - 20", "21 - 50", "More than 50"}},
+				),
 			},
 		},
 
@@ -162,8 +247,11 @@ func GetSiteContent() []Page {
 			Title:       "SARS E-Filing Registration & Profile Setup | SA Tax Returns",
 			Description: "Need help getting on SARS E-Filing? We register profiles, fix login issues, and merge existing tax types.",
 			Path:        "registrations/efiling/index.html",
+			Terms:       map[string][]string{"service": {"eFiling Setup"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "E-Filing Setup", Order: 31},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "E-Filing Setup & Support", Subtitle: "We get you registered and set up on SARS E-Filing correctly the first time.", PrimaryBtn: "Activate Profile"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Get Connected to SARS",
 					Paragraphs: []string{
@@ -171,14 +259,21 @@ func GetSiteContent() []Page {
 						"One of the most common issues is 'broken' profiles where tax types (like VAT or PAYE) aren't linked. Our practitioners have a dedicated channel to resolve these profile issues quickly.",
 					},
 				}},
+				serviceForm("Get eFiling Set Up", "Tell us what you need and we'll get you on eFiling.", "Send My Details",
+					forms.Field{Name: "need", Label: "What do you need?", Type: forms.TypeSelect, Required: true, Options: []string{"New eFiling profile", "Reset or recover my profile", "Register as a tax practitioner", "Something else"}},
+					forms.Field{Name: "for_business", Label: "This profile is for a business", Type: forms.TypeCheckbox},
+				),
 			},
 		},
 		{
 			Title:       "Company Tax Registration (Income Tax) | SA Tax Returns",
 			Description: "Register your new CIPC company for Income Tax with SARS. Obtain your Tax Reference Number quickly.",
 			Path:        "registrations/company-tax/index.html",
+			Terms:       map[string][]string{"service": {"Company Tax Registration"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "Company Tax Reg", Order: 32},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "Company Income Tax Registration", Subtitle: "Ensure your new business complies with the Tax Administration Act.", PrimaryBtn: "Register Company"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Mandatory Tax Registration",
 					Paragraphs: []string{
@@ -186,14 +281,22 @@ func GetSiteContent() []Page {
 						"We ensure your Representative Vendor is appointed correctly at SARS, which is now a strict requirement before you can file any returns. Validating the Public Officer is the critical first step to corporate compliance.",
 					},
 				}},
+				serviceForm("Register Your Company for Tax", "Send us your company details and we'll handle the SARS registration.", "Start Registration",
+					forms.Field{Name: "company", Label: "Company name", Required: true},
+					forms.Field{Name: "cipc_number", Label: "CIPC registration number", Placeholder: "2024/123456/07", MaxLength: 20},
+					forms.Field{Name: "cor", Label: "CIPC registration certificate (CoR14.3)", Type: forms.TypeFile, Accept: []string{".pdf"}, Help: "Optional. Please don't upload ID documents or bank statements; we'll ask for them securely if needed."},
+				),
 			},
 		},
 		{
 			Title:       "VAT Registration Services (Voluntary & Mandatory) | SA Tax Returns",
 			Description: "Fast VAT registration for South African companies. Expert assistance with the RAV01 form and interview process.",
 			Path:        "registrations/vat/index.html",
+			Terms:       map[string][]string{"service": {"VAT Registration"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "VAT Registration", Order: 33},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "VAT Registration", Subtitle: "Navigate the complex SARS VAT registration process with expert guidance.", PrimaryBtn: "Register for VAT"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Understanding VAT Registration",
 					Paragraphs: []string{
@@ -201,14 +304,21 @@ func GetSiteContent() []Page {
 						"SARS strict verification process often result in rejections. We prepare your invoices, bank statements, and business contracts to meet the specific requirements of the RAV01 form, ensuring your application is approved without delay.",
 					},
 				}},
+				serviceForm("Register for VAT", "Send us your business details and we'll prepare your VAT application.", "Start Registration",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "registration_type", Label: "Registration type", Type: forms.TypeSelect, Required: true, Options: []string{"Mandatory (turnover over R1 million)", "Voluntary (turnover over R50,000)", "Not sure"}},
+				),
 			},
 		},
 		{
 			Title:       "PAYE Employer Registration (EMP101) | SA Tax Returns",
 			Description: "Register as an employer with SARS. PAYE, SDL, and UIF registration in one smooth process.",
 			Path:        "registrations/paye/index.html",
+			Terms:       map[string][]string{"service": {"PAYE Registration"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "PAYE Registration", Order: 34},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "PAYE Employer Registration", Subtitle: "Hiring your first employee? You need to register for PAYE within 21 days.", PrimaryBtn: "Register Employer"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Becoming an Employer",
 					Paragraphs: []string{
@@ -216,14 +326,22 @@ func GetSiteContent() []Page {
 						"This registration (EMP101) covers three levies: PAYE (Tax), SDL (Skills Development Levy - for payrolls > R500k/yr), and UIF (Unemployment Insurance). We set this up correctly so your monthly payroll runs smoothly.",
 					},
 				}},
+				serviceForm("Register as an Employer", "Send us your business details and we'll register you for PAYE, SDL and UIF.", "Start Registration",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "employees", Label: "Number of employees", Type: forms.TypeSelect, Required: true, Options: []string{"1 - 5", "6 - 20", "21 - 50", "More than 50"}},
+					forms.Field{Name: "first_payday", Label: "First payday", Placeholder: "e.g. 25 November"},
+				),
 			},
 		},
 		{
 			Title:       "UIF Registration (Dept of Labour) | SA Tax Returns",
 			Description: "Register your domestic or commercial workers for UIF. Protect your staff and comply with labour laws.",
 			Path:        "registrations/uif/index.html",
+			Terms:       map[string][]string{"service": {"UIF Registration"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "UIF Registration", Order: 35},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "UIF Registration", Subtitle: "Department of Labour registration for all employers.", PrimaryBtn: "Register UIF"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Protecting Your Workforce",
 					Paragraphs: []string{
@@ -231,14 +349,22 @@ func GetSiteContent() []Page {
 						"This is crucial: if your employees are not registered here, they cannot claim benefits if they are retrenched or take maternity leave. We handle both the SARS and uFiling sides of the registration.",
 					},
 				}},
+				serviceForm("Register for UIF", "Send us your business details and we'll register you with the Department of Labour.", "Start Registration",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "employees", Label: "Number of employees", Type: forms.TypeSelect, Required: true, Options: []string{"1 - 5", "6 - 20", "21 - 50", "More than 50"}},
+					forms.Field{Name: "domestic", Label: "I employ domestic workers", Type: forms.TypeCheckbox},
+				),
 			},
 		},
 		{
 			Title:       "WCA Registration (COIDA) | SA Tax Returns",
 			Description: "Workmen's Compensation (COIDA) registration and Letter of Good Standing.",
 			Path:        "registrations/wca/index.html",
+			Terms:       map[string][]string{"service": {"COIDA Registration"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "WCA (Workmen's Comp)", Order: 36},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "WCA / COIDA Registration", Subtitle: "Workmen's Compensation is mandatory for any business with employees.", PrimaryBtn: "Get Coverage"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Injury on Duty Protection",
 					Paragraphs: []string{
@@ -246,14 +372,22 @@ func GetSiteContent() []Page {
 						"To bid for most tenders and contracts, you need a Letter of Good Standing. We register you with the Compensation Fund and help you submit your Return of Earnings to obtain your letter.",
 					},
 				}},
+				serviceForm("Register for COIDA", "Send us your business details and we'll handle your Compensation Fund registration.", "Start Registration",
+					forms.Field{Name: "company", Label: "Business name", Required: true},
+					forms.Field{Name: "industry", Label: "Industry", Required: true, Placeholder: "e.g. Construction, Retail"},
+					forms.Field{Name: "employees", Label: "Number of employees", Type: forms.TypeSelect, Required: true, Options: []string{"1 - 5", "6 - 20", "21 - 50", "More than 50"}},
+				),
 			},
 		},
 		{
 			Title:       "CIPC New Company Registration | SA Tax Returns",
 			Description: "Register a Pty Ltd company in South Africa. Includes Name Reservation and Share Certificates.",
 			Path:        "registrations/new-company/index.html",
+			Terms:       map[string][]string{"service": {"Company Registration (CIPC)"}},
+			Nav:         NavOptions{Group: "Registrations", Label: "New Company (CIPC)", Order: 37},
 			Sections: []Section{
 				{TemplateName: "hero", Data: HeroData{Title: "New Company (CIPC)", Subtitle: "Start your business journey with a formally registered Pty Ltd.", PrimaryBtn: "Register Company"}},
+				{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
 				{TemplateName: "text_block", Data: TextBlockData{
 					Heading: "Start Your Business",
 					Paragraphs: []string{
@@ -261,37 +395,77 @@ func GetSiteContent() []Page {
 						"We also issue your Share Certificates (required by banks) and assist with the initial B-BBEE affidavit. Get your business trading legally in as little as 3-5 days.",
 					},
 				}},
+				serviceForm("Register a New Company", "Tell us about your new company and we'll handle the CIPC registration.", "Start Registration",
+					forms.Field{Name: "company", Label: "Proposed company name", Required: true, Help: "We'll reserve the name with CIPC first; add alternatives in the message."},
+					forms.Field{Name: "directors", Label: "Number of directors", Type: forms.TypeSelect, Required: true, Options: []string{"1", "2", "3 or more"}},
+				),
 			},
 		},
 	}
 }
 
+// serviceForm is the enquiry form of a service page: name, email and phone,
+// the service's own fields, then a free-text message.
+func serviceForm(title, intro, button string, fields ...forms.Field) Section {
+	all := []forms.Field{
+		{Name: "name", Label: "Full name", Required: true},
+		{Name: "email", Label: "Email", Type: forms.TypeEmail, Required: true},
+		{Name: "phone", Label: "Phone", Type: forms.TypePhone, Placeholder: "082 123 4567"},
+	}
+	all = append(all, fields...)
+	all = append(all, forms.Field{Name: "message", Label: "Anything else we should know?", Type: forms.TypeTextarea})
+	return Section{TemplateName: "form", Data: FormData{Title: title, Intro: intro, ButtonText: button, Fields: all}}
+}
+
 // --- Section Data Structs ---
 
 type HeroData struct {
-	Title           string
-	Subtitle        string
-	PrimaryBtn      string
-	SecondaryBtn    string
-	BackgroundImage string
+	Title           string `yaml:"title"`
+	Subtitle        string `yaml:"subtitle"`
+	PrimaryBtn      string `yaml:"primary_btn"`
+	PrimaryLink     string `yaml:"primary_link"` // defaults to the page's form, if it has one
+	SecondaryBtn    string `yaml:"secondary_btn"`
+	SecondaryLink   string `yaml:"secondary_link"`
+	BackgroundImage string `yaml:"background_image"`
 }
 
 type TextBlockData struct {
-	Heading    string
-	Paragraphs []string
+	Heading    string   `yaml:"heading"`
+	Paragraphs []string `yaml:"paragraphs"`
 }
 
 type FeaturesData struct {
-	Title string
-	Items []FeatureItem
+	Title string        `yaml:"title"`
+	Items []FeatureItem `yaml:"items"`
 }
 
 type FeatureItem struct {
-	Name        string
-	Description string
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Link        string `yaml:"link"` // optional; makes the whole card a link
+}
+
+// FormData is a form posted to the form server (cmd/formserver), built from
+// the fields it lists. The form server validates posts against the same
+// definitions through the manifest written by collectForms (forms.go).
+// Empty settings get defaults in prepareForms.
+type FormData struct {
+	Title       string        `yaml:"title"`
+	Intro       string        `yaml:"intro"`
+	ButtonText  string        `yaml:"button_text"`
+	ID          string        `yaml:"id"`           // form ID and anchor, defaults to the page ID
+	Kind        string        `yaml:"kind"`         // "listing" for directory applications, "review" for reviews, empty for enquiries
+	Subject     string        `yaml:"subject"`      // what a review form reviews: a practitioner slug
+	Action      string        `yaml:"action"`       // URL the form posts to
+	Fields      []forms.Field `yaml:"fields"`       // see internal/forms for the field types
+	SuccessPage string        `yaml:"success_page"` // page ID to redirect to, defaults to a generated thank-you page
+	Notify      []string      `yaml:"notify"`       // who is emailed about submissions, instead of the form server's default
+	Consent     *Privacy      `yaml:"-"`            // privacy notice to accept, from the site settings
 }
 
+// ContactFormData is the site's general enquiry form. It renders like a form
+// section, with the default contact fields and intro when it doesn't set its
+// own.
 type ContactFormData struct {
-	Title      string
-	ButtonText string
+	FormData `yaml:",inline"`
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Live reload events sent to the browser.
+const (
+	eventReload = "reload" // pages changed, reload the document
+	eventCSS    = "css"    // only the stylesheet changed, swap it in place
+)
+
+// liveReloadPath is the SSE endpoint the injected client listens on.
+const liveReloadPath = "/__livereload"
+
+// liveReloadScript is injected into every HTML page served in -dev mode.
+// It is never written to pages/ or build/. A dropped connection means the
+// server restarted, so the page reloads once it reconnects.
+const liveReloadScript = `<script>
+(function () {
+    var source = new EventSource("` + liveReloadPath + `");
+    var dropped = false;
+    source.onerror = function () { dropped = true; };
+    source.onopen = function () { if (dropped) location.reload(); };
+    source.addEventListener("` + eventReload + `", function () { location.reload(); });
+    source.addEventListener("` + eventCSS + `", function () {
+        document.querySelectorAll('link[rel="stylesheet"]').forEach(function (link) {
+            var url = new URL(link.href);
+            url.searchParams.set("v", Date.now());
+            link.href = url.toString();
+        });
+    });
+})();
+</script>
+`
+
+// reloadBroker fans build events out to every connected browser.
+type reloadBroker struct {
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+}
+
+func newReloadBroker() *reloadBroker {
+	return &reloadBroker{clients: make(map[chan string]struct{})}
+}
+
+// Publish sends event to all connected clients without blocking on slow ones.
+func (b *reloadBroker) Publish(event string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *reloadBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan string, 1)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	for {
+		select {
+		case event := <-ch:
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", event)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// devHandler serves the build directory, injecting the live reload client
+// into HTML documents. While the latest build is failing, HTML requests get
+// the error overlay instead of the stale pages.
+type devHandler struct {
+	root   string
+	files  http.Handler
+	status *buildStatus
+}
+
+func newDevHandler(root string, status *buildStatus) *devHandler {
+	return &devHandler{root: root, files: http.FileServer(http.Dir(root)), status: status}
+}
+
+func (h *devHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Pages are published at their directory, like production
+	if path.Base(r.URL.Path) == "index.html" {
+		http.Redirect(w, r, strings.TrimSuffix(r.URL.Path, "index.html"), http.StatusMovedPermanently)
+		return
+	}
+
+	name := filepath.Join(h.root, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			// Let the file server issue its trailing-slash redirect
+			h.files.ServeHTTP(w, r)
+			return
+		}
+		name = filepath.Join(name, "index.html")
+	}
+
+	if !strings.HasSuffix(name, ".html") {
+		h.files.ServeHTTP(w, r)
+		return
+	}
+	if err := h.status.Err(); err != nil {
+		serveOverlay(w, err)
+		return
+	}
+	html, err := os.ReadFile(name)
+	if err != nil {
+		h.files.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(injectScript(html, liveReloadScript))
+}
+
+// injectScript inserts script just before </body>, or appends it if the
+// document has no closing body tag.
+func injectScript(html []byte, script string) []byte {
+	i := bytes.LastIndex(html, []byte("</body>"))
+	if i < 0 {
+		return append(html, script...)
+	}
+	out := make([]byte, 0, len(html)+len(script))
+	out = append(out, html[:i]...)
+	out = append(out, script...)
+	return append(out, html[i:]...)
+}
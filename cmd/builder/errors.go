@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is a single failure found during a build.
+type Problem struct {
+	Page     string // page Path, empty for site-wide problems
+	Template string // template being executed or validated, if any
+	Err      error
+}
+
+func (p Problem) String() string {
+	var where []string
+	if p.Page != "" {
+		where = append(where, p.Page)
+	}
+	if p.Template != "" {
+		where = append(where, "["+p.Template+"]")
+	}
+	if len(where) == 0 {
+		return p.Err.Error()
+	}
+	return strings.Join(where, " ") + ": " + p.Err.Error()
+}
+
+// BuildError collects every problem from a failed build so they can be
+// reported together instead of stopping at the first one.
+type BuildError struct {
+	Problems []Problem
+}
+
+func (e *BuildError) Add(page, tmpl string, err error) {
+	e.Problems = append(e.Problems, Problem{Page: page, Template: tmpl, Err: err})
+}
+
+// Err returns nil when no problems were recorded.
+func (e *BuildError) Err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *BuildError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("build failed with %d problem(s):\n  %s", len(e.Problems), strings.Join(lines, "\n  "))
+}
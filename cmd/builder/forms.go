@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"website/internal/forms"
+)
+
+// formsManifest lists every form the site renders, for the form server
+// (cmd/formserver) to validate submissions against. It is generated; commit
+// it with pages/.
+const formsManifest = "data/forms.json"
+
+// defaultContactFields are used by contact_form sections that don't list
+// their own fields.
+var defaultContactFields = []forms.Field{
+	{Name: "name", Label: "Name", Placeholder: "John Doe", Required: true},
+	{Name: "email", Label: "Email", Type: forms.TypeEmail, Placeholder: "john@example.com", Required: true},
+	{Name: "message", Label: "Message", Type: forms.TypeTextarea, Placeholder: "How can we help you?", Required: true},
+}
+
+// defaultContactIntro is the intro of contact_form sections without one.
+const defaultContactIntro = "We'd love to hear from you. Send us a message below."
+
+// sectionForm returns the form of a form or contact_form section.
+func sectionForm(s Section) (FormData, bool) {
+	switch data := s.Data.(type) {
+	case FormData:
+		return data, true
+	case ContactFormData:
+		return data.FormData, true
+	}
+	return FormData{}, false
+}
+
+// withForm returns s with its form replaced by form.
+func withForm(s Section, form FormData) Section {
+	switch s.Data.(type) {
+	case FormData:
+		s.Data = form
+	case ContactFormData:
+		s.Data = ContactFormData{form}
+	}
+	return s
+}
+
+// FormInput is a field as rendered by the form_field template.
+type FormInput struct {
+	forms.Field
+	ID string // HTML id, unique on the page
+}
+
+// Inputs are the form's fields with their HTML ids.
+func (f FormData) Inputs() []FormInput {
+	inputs := make([]FormInput, len(f.Fields))
+	for i, field := range f.Fields {
+		inputs[i] = FormInput{Field: field, ID: f.ID + "-" + field.Name}
+	}
+	return inputs
+}
+
+// Multipart reports whether the form uploads files.
+func (f FormData) Multipart() bool {
+	return forms.Form{Fields: f.Fields}.HasFiles()
+}
+
+// prepareForms fills in defaults for every form and contact_form section: an
+// ID from the page, the form server action, the default fields and intro
+// for contact forms, the site's privacy notice, and a generated thank-you page when the
+// form doesn't name its own success page. Hero buttons without a link point
+// at the page's first form. It returns the generated pages.
+func prepareForms(pages []Page, site Site) []Page {
+	var generated []Page
+	for i := range pages {
+		page := &pages[i]
+		firstForm, thanksID := "", ""
+		for j, s := range page.Sections {
+			data, ok := sectionForm(s)
+			if !ok {
+				continue
+			}
+			if data.ID == "" {
+				data.ID = strings.ReplaceAll(page.PageID(), "/", "-")
+			}
+			if data.Action == "" {
+				data.Action = site.FormsURL + "/forms/" + data.ID
+			}
+			if _, contact := s.Data.(ContactFormData); contact {
+				if len(data.Fields) == 0 {
+					data.Fields = defaultContactFields
+				}
+				if data.Intro == "" {
+					data.Intro = defaultContactIntro
+				}
+			}
+			if site.Privacy.Version != "" {
+				privacy := site.Privacy
+				data.Consent = &privacy
+			}
+			if data.SuccessPage == "" {
+				if thanksID == "" {
+					thanks := thankYouPage(*page, site)
+					thanksID = thanks.PageID()
+					generated = append(generated, thanks)
+				}
+				data.SuccessPage = thanksID
+			}
+			if firstForm == "" {
+				firstForm = data.ID
+			}
+			page.Sections[j] = withForm(s, data)
+		}
+
+		if firstForm == "" {
+			continue
+		}
+		for j, s := range page.Sections {
+			if hero, ok := s.Data.(HeroData); ok && hero.PrimaryBtn != "" && hero.PrimaryLink == "" {
+				hero.PrimaryLink = "#" + firstForm
+				page.Sections[j].Data = hero
+			}
+		}
+	}
+	return generated
+}
+
+// thankYouPage is shown after a form on page is submitted. It is kept out of
+// the navigation and the sitemap.
+func thankYouPage(page Page, site Site) Page {
+	dir := strings.TrimSuffix(page.Path, path.Base(page.Path))
+	return Page{
+		Title:       "Thank You | " + site.Name,
+		Description: "Your message has been received.",
+		Path:        dir + "thank-you/index.html",
+		Nav:         NavOptions{Hide: true},
+		Sitemap:     SitemapOptions{Exclude: true},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{
+				Title:    "Thank You",
+				Subtitle: "We've received your message and one of our team will be in touch soon.",
+			}},
+		},
+		source: "generated thank-you page for " + page.Path,
+	}
+}
+
+// collectForms builds the manifest of every form on the site, recording
+// invalid or duplicate forms in errs.
+func collectForms(pages []Page, pageURLs map[string]string, errs *BuildError) forms.Manifest {
+	manifest := make(forms.Manifest)
+	for _, page := range pages {
+		for _, s := range page.Sections {
+			data, ok := sectionForm(s)
+			if !ok {
+				continue
+			}
+			success, ok := pageURLs[data.SuccessPage]
+			if !ok {
+				errs.Add(page.Path, s.TemplateName, fmt.Errorf("form %s: no page with id %q for success_page", data.ID, data.SuccessPage))
+				continue
+			}
+			form := forms.Form{ID: data.ID, Kind: data.Kind, Subject: data.Subject, Page: page.URL(), Success: success, Fields: data.Fields, Notify: data.Notify}
+			if data.Consent != nil {
+				notice, ok := pageURLs[data.Consent.Notice]
+				if !ok {
+					errs.Add(page.Path, s.TemplateName, fmt.Errorf("form %s: no page with id %q for the privacy notice", data.ID, data.Consent.Notice))
+					continue
+				}
+				form.Consent = &forms.Consent{Version: data.Consent.Version, Notice: notice}
+			}
+			if err := form.Check(); err != nil {
+				errs.Add(page.Path, s.TemplateName, err)
+				continue
+			}
+			if prev, ok := manifest[form.ID]; ok {
+				errs.Add(page.Path, s.TemplateName, fmt.Errorf("form id %q is also used on %s", form.ID, prev.Page))
+				continue
+			}
+			manifest[form.ID] = form
+		}
+	}
+	return manifest
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"website/internal/forms"
+)
+
+func TestPrepareForms(t *testing.T) {
+	site := Site{Name: "SA Tax Returns", FormsURL: "https://forms.example.co.za", Privacy: Privacy{Notice: "privacy", Version: "2026-01"}}
+	fields := []forms.Field{{Name: "turnover", Label: "Turnover", Type: forms.TypeSelect, Options: []string{"Low", "High"}}}
+	pages := []Page{
+		{Path: "contact/index.html", Sections: []Section{
+			{TemplateName: "contact_form", Data: ContactFormData{}},
+		}},
+		{Path: "registrations/vat/index.html", Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{Title: "VAT", PrimaryBtn: "Start"}},
+			{TemplateName: "form", Data: FormData{Fields: fields}},
+			{TemplateName: "form", Data: FormData{ID: "callback", Fields: fields, SuccessPage: "contact"}},
+		}},
+	}
+	generated := prepareForms(pages, site)
+
+	contact := pages[0].Sections[0].Data.(ContactFormData)
+	if len(contact.Fields) != len(defaultContactFields) || contact.Intro != defaultContactIntro {
+		t.Errorf("contact form without fields got %+v", contact.FormData)
+	}
+	vat := pages[1].Sections[1].Data.(FormData)
+	if vat.ID != "registrations-vat" || vat.Action != "https://forms.example.co.za/forms/registrations-vat" {
+		t.Errorf("form ID %q, action %q", vat.ID, vat.Action)
+	}
+	if vat.Consent == nil || vat.Consent.Version != "2026-01" {
+		t.Errorf("consent = %+v, want the site's privacy notice", vat.Consent)
+	}
+	if len(vat.Fields) != 1 || vat.Fields[0].Name != "turnover" {
+		t.Errorf("form section got the contact fields: %+v", vat.Fields)
+	}
+	if callback := pages[1].Sections[2].Data.(FormData); callback.SuccessPage != "contact" {
+		t.Errorf("own success page replaced by %q", callback.SuccessPage)
+	}
+	if hero := pages[1].Sections[0].Data.(HeroData); hero.PrimaryLink != "#registrations-vat" {
+		t.Errorf("hero button links to %q, want the first form", hero.PrimaryLink)
+	}
+
+	var paths []string
+	for _, page := range generated {
+		paths = append(paths, page.Path)
+	}
+	// One thank-you page per page, however many forms it has
+	if want := []string{"contact/thank-you/index.html", "registrations/vat/thank-you/index.html"}; !slices.Equal(paths, want) {
+		t.Fatalf("generated %q, want %q", paths, want)
+	}
+	if vat.SuccessPage != generated[1].PageID() {
+		t.Errorf("success page %q, want the generated %q", vat.SuccessPage, generated[1].PageID())
+	}
+	if thanks := generated[1]; !thanks.Nav.Hide || !thanks.Sitemap.Exclude {
+		t.Errorf("thank-you page is in the nav or sitemap: %+v", thanks)
+	}
+}
+
+func TestCollectForms(t *testing.T) {
+	urls := map[string]string{"contact/thank-you": "/contact/thank-you/", "privacy": "/privacy/"}
+	form := func(id, success string) Section {
+		return Section{TemplateName: "form", Data: FormData{ID: id, SuccessPage: success, Fields: []forms.Field{{Name: "name", Label: "Name"}}}}
+	}
+	pages := []Page{
+		{Path: "contact/index.html", Sections: []Section{
+			form("contact", "contact/thank-you"),
+			{TemplateName: "contact_form", Data: ContactFormData{FormData{ID: "callback", SuccessPage: "contact/thank-you", Consent: &Privacy{Notice: "privacy", Version: "2026-01"}}}},
+		}},
+		{Path: "about/index.html", Sections: []Section{
+			form("contact", "contact/thank-you"), // duplicate ID
+			form("about", "about/thanks"),        // unknown success page
+			{TemplateName: "form", Data: FormData{ID: "broken", SuccessPage: "contact/thank-you", Fields: []forms.Field{{Name: "x", Type: "date"}}}},
+			{TemplateName: "form", Data: FormData{ID: "consent", SuccessPage: "contact/thank-you", Consent: &Privacy{Notice: "policy", Version: "2026-01"}}},
+		}},
+	}
+	var errs BuildError
+	manifest := collectForms(pages, urls, &errs)
+
+	if got := manifest["callback"]; got.Page != "/contact/" || got.Success != "/contact/thank-you/" || got.Consent == nil || got.Consent.Notice != "/privacy/" {
+		t.Errorf("callback form = %+v", got)
+	}
+	if len(manifest) != 2 {
+		t.Errorf("manifest has %d forms, want contact and callback", len(manifest))
+	}
+	var problems []string
+	for _, p := range errs.Problems {
+		problems = append(problems, p.Page+": "+p.Err.Error())
+	}
+	want := []string{
+		`about/index.html: form id "contact" is also used on /contact/`,
+		`about/index.html: form about: no page with id "about/thanks" for success_page`,
+		`about/index.html: form broken: field x has unknown type "date"`,
+		`about/index.html: form consent: no page with id "policy" for the privacy notice`,
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems:\n got %q\nwant %q", problems, want)
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"website/internal/reviews"
+)
+
+// JSON-LD entities for Google rich results. base.html embeds each value
+// returned by PageView.StructuredData in its own
+// <script type="application/ld+json">; html/template marshals and escapes it.
+
+const schemaContext = "https://schema.org"
+
+// servicePageDirs are the top-level directories whose pages describe a
+// service the business offers.
+var servicePageDirs = []string{"submissions", "registrations"}
+
+type ldBusiness struct {
+	Context    string     `json:"@context"`
+	Type       string     `json:"@type"`
+	ID         string     `json:"@id"`
+	Name       string     `json:"name"`
+	URL        string     `json:"url"`
+	Logo       string     `json:"logo,omitempty"`
+	Telephone  string     `json:"telephone,omitempty"`
+	Email      string     `json:"email,omitempty"`
+	Address    *ldAddress `json:"address,omitempty"`
+	AreaServed []string   `json:"areaServed,omitempty"`
+}
+
+type ldAddress struct {
+	Type          string `json:"@type"`
+	StreetAddress string `json:"streetAddress,omitempty"`
+	Locality      string `json:"addressLocality,omitempty"`
+	Region        string `json:"addressRegion,omitempty"`
+	PostalCode    string `json:"postalCode,omitempty"`
+	Country       string `json:"addressCountry,omitempty"`
+}
+
+type ldService struct {
+	Context     string   `json:"@context"`
+	Type        string   `json:"@type"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url"`
+	Provider    ldRef    `json:"provider"`
+	AreaServed  []string `json:"areaServed,omitempty"`
+}
+
+// ldPractitioner describes a listed practice and the practitioner behind it.
+type ldPractitioner struct {
+	Context       string   `json:"@context"`
+	Type          string   `json:"@type"`
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	Image         string   `json:"image,omitempty"`
+	Telephone     string   `json:"telephone,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	SameAs        string   `json:"sameAs,omitempty"`
+	AreaServed    []string `json:"areaServed"`
+	KnowsLanguage []string `json:"knowsLanguage,omitempty"`
+	Employee      ldPerson `json:"employee"`
+
+	AggregateRating *ldAggregateRating `json:"aggregateRating,omitempty"`
+	Review          []ldReview         `json:"review,omitempty"`
+}
+
+type ldAggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	ReviewCount int     `json:"reviewCount"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+type ldReview struct {
+	Type          string   `json:"@type"`
+	Author        ldAuthor `json:"author"`
+	ReviewRating  ldRating `json:"reviewRating"`
+	ReviewBody    string   `json:"reviewBody"`
+	DatePublished string   `json:"datePublished"`
+}
+
+type ldAuthor struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type ldRating struct {
+	Type        string `json:"@type"`
+	RatingValue int    `json:"ratingValue"`
+}
+
+type ldPerson struct {
+	Type          string       `json:"@type"`
+	Name          string       `json:"name"`
+	HasCredential ldCredential `json:"hasCredential"`
+}
+
+type ldCredential struct {
+	Type               string `json:"@type"`
+	CredentialCategory string `json:"credentialCategory"`
+	Name               string `json:"name"`
+	RecognizedBy       ldOrg  `json:"recognizedBy"`
+}
+
+type ldOrg struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type ldRef struct {
+	ID string `json:"@id"`
+}
+
+type ldBreadcrumbList struct {
+	Context string       `json:"@context"`
+	Type    string       `json:"@type"`
+	Items   []ldListItem `json:"itemListElement"`
+}
+
+type ldListItem struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Item     string `json:"item,omitempty"`
+}
+
+// StructuredData lists the JSON-LD entities for the page: the business on
+// every page, a Service on service pages, the practice on practitioner
+// profiles, and the page's breadcrumbs.
+func (v PageView) StructuredData() []any {
+	data := []any{v.businessLD()}
+	if v.isServicePage() {
+		data = append(data, ldService{
+			Context:     schemaContext,
+			Type:        "Service",
+			Name:        v.ShortTitle(),
+			Description: v.Description,
+			URL:         v.CanonicalURL(),
+			Provider:    ldRef{ID: v.businessID()},
+			AreaServed:  v.Site.AreaServed,
+		})
+	}
+	for _, s := range v.Sections {
+		if p, ok := s.Data.(PractitionerData); ok {
+			data = append(data, v.practitionerLD(p))
+		}
+	}
+	if crumbs := v.breadcrumbLD(); len(crumbs.Items) > 1 {
+		data = append(data, crumbs)
+	}
+	return data
+}
+
+func (v PageView) businessID() string {
+	return v.Site.AbsURL("/#business")
+}
+
+func (v PageView) businessLD() ldBusiness {
+	b := ldBusiness{
+		Context:    schemaContext,
+		Type:       "AccountingService",
+		ID:         v.businessID(),
+		Name:       v.Site.Name,
+		URL:        v.Site.AbsURL("/"),
+		Telephone:  v.Site.Phone,
+		Email:      v.Site.Email,
+		AreaServed: v.Site.AreaServed,
+	}
+	if v.Site.Logo != "" {
+		b.Logo = v.Site.AbsURL(v.Site.Logo)
+	}
+	if a := v.Site.Address; a != (Address{}) {
+		b.Address = &ldAddress{
+			Type:          "PostalAddress",
+			StreetAddress: a.Street,
+			Locality:      a.Locality,
+			Region:        a.Region,
+			PostalCode:    a.PostalCode,
+			Country:       a.Country,
+		}
+	}
+	return b
+}
+
+func (v PageView) practitionerLD(p PractitionerData) ldPractitioner {
+	ld := ldPractitioner{
+		Context:       schemaContext,
+		Type:          "AccountingService",
+		Name:          p.Practice,
+		URL:           v.CanonicalURL(),
+		Telephone:     p.Contact.Phone,
+		Email:         p.Contact.Email,
+		SameAs:        p.Contact.Website,
+		AreaServed:    p.Areas,
+		KnowsLanguage: p.Languages,
+		Employee: ldPerson{
+			Type: "Person",
+			Name: p.Name,
+			HasCredential: ldCredential{
+				Type:               "EducationalOccupationalCredential",
+				CredentialCategory: "Professional registration",
+				Name:               p.Registration.String(),
+				RecognizedBy:       ldOrg{Type: "Organization", Name: p.Registration.Body},
+			},
+		},
+	}
+	if p.Photo != "" {
+		ld.Image = v.Site.AbsURL(p.Photo)
+	}
+	if rating := p.Rating(); rating.Count > 0 {
+		ld.AggregateRating = &ldAggregateRating{
+			Type:        "AggregateRating",
+			RatingValue: rating.Average,
+			ReviewCount: rating.Count,
+			BestRating:  reviews.MaxRating,
+			WorstRating: reviews.MinRating,
+		}
+	}
+	for _, r := range p.Reviews {
+		ld.Review = append(ld.Review, ldReview{
+			Type:          "Review",
+			Author:        ldAuthor{Type: "Person", Name: r.Name},
+			ReviewRating:  ldRating{Type: "Rating", RatingValue: r.Rating},
+			ReviewBody:    r.Text,
+			DatePublished: r.Date.Format("2006-01-02"),
+		})
+	}
+	return ld
+}
+
+// isServicePage reports whether the page describes a service: it is below
+// one of servicePageDirs and is meant to be found, unlike the thank-you
+// pages there.
+func (v PageView) isServicePage() bool {
+	if v.Sitemap.Exclude {
+		return false
+	}
+	top, _, _ := strings.Cut(strings.Trim(v.URL(), "/"), "/")
+	for _, dir := range servicePageDirs {
+		if top == dir && v.URL() != "/"+dir+"/" {
+			return true
+		}
+	}
+	return false
+}
+
+// breadcrumbLD describes the page's breadcrumb trail. Steps without a page
+// of their own are listed by name only.
+func (v PageView) breadcrumbLD() ldBreadcrumbList {
+	list := ldBreadcrumbList{Context: schemaContext, Type: "BreadcrumbList"}
+	for i, crumb := range v.Breadcrumbs {
+		item := ldListItem{Type: "ListItem", Position: i + 1, Name: crumb.Label}
+		if crumb.URL != "" {
+			item.Item = v.Site.AbsURL(crumb.URL)
+		}
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+// ShortTitle is a compact name for the page: the hero heading if there is
+// one, else the title without its " | Brand" suffix.
+func (p Page) ShortTitle() string {
+	for _, s := range p.Sections {
+		if hero, ok := s.Data.(HeroData); ok && hero.Title != "" {
+			return hero.Title
+		}
+	}
+	return p.plainTitle()
+}
+
+// plainTitle is the title without its " | Brand" suffix.
+func (p Page) plainTitle() string {
+	title, _, _ := strings.Cut(p.Title, " | ")
+	return title
+}
+
+var ldScript = regexp.MustCompile(`(?s)<script type="application/ld\+json">(.*?)</script>`)
+
+// checkStructuredData verifies every JSON-LD block in a rendered page parses.
+func checkStructuredData(html []byte) error {
+	for i, m := range ldScript.FindAllSubmatch(html, -1) {
+		if !json.Valid(m[1]) {
+			return fmt.Errorf("JSON-LD block %d is not valid JSON", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ChildPagesData is the data for the "child_pages" section: a card for each
+// page directly below the current one. The builder fills Items unless a
+// generated page sets them; Order lists page IDs to show first, the rest
+// follow in nav order.
+type ChildPagesData struct {
+	Title string   `yaml:"title"`
+	Intro string   `yaml:"intro"`
+	Order []string `yaml:"order"`
+	Items []Card   `yaml:"-"`
+}
+
+// Card links to another page.
+type Card struct {
+	Title       string
+	Description string
+	URL         string
+}
+
+// landingPages generates an index page for every directory that has pages
+// below it, at any depth, but none of its own, e.g. /registrations/. To
+// change the copy or card order, add a real page at that path with a
+// "child_pages" section.
+func landingPages(pages []Page, site Site) []Page {
+	byURL := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		byURL[page.URL()] = true
+	}
+
+	var landings []Page
+	for _, page := range pages {
+		for dir := parentURL(page.URL()); dir != "" && dir != "/"; dir = parentURL(dir) {
+			if byURL[dir] {
+				continue
+			}
+			byURL[dir] = true
+			landings = append(landings, landingPage(dir, site))
+		}
+	}
+	return landings
+}
+
+func landingPage(dir string, site Site) Page {
+
+	label := dirLabel(path.Base(dir))
+	return Page{
+		Title:       label + " | " + site.Name,
+		Description: fmt.Sprintf("%s services from %s. Choose a service to see how our tax practitioners can help.", label, site.Name),
+		Path:        strings.TrimPrefix(dir, "/") + "index.html",
+		Nav:         NavOptions{Hide: true},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{Title: label, Subtitle: "Choose a service below to see how we can help."}},
+			{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+			{TemplateName: "child_pages", Data: ChildPagesData{}},
+		},
+		source: "generated landing page",
+	}
+}
+
+// parentURL returns the URL of the directory above url, "" for the root.
+func parentURL(url string) string {
+	if url == "/" {
+		return ""
+	}
+	dir := path.Dir(strings.TrimSuffix(url, "/"))
+	if dir == "/" {
+		return "/"
+	}
+	return dir + "/"
+}
+
+// withChildPages returns the page's sections with cards filled into any
+// "child_pages" section.
+func withChildPages(sections []Section, page Page, pages []Page) []Section {
+	out := make([]Section, len(sections))
+	for i, s := range sections {
+		out[i] = s
+		if data, ok := s.Data.(ChildPagesData); ok && len(data.Items) == 0 {
+			data.Items = childCards(page, pages, data.Order)
+			out[i].Data = data
+		}
+	}
+	return out
+}
+
+// childPages returns the pages directly below parent that are listed in the
+// sitemap.
+func childPages(parent Page, pages []Page) []Page {
+	var children []Page
+	for _, p := range pages {
+		if parentURL(p.URL()) == parent.URL() && !p.Sitemap.Exclude {
+			children = append(children, p)
+		}
+	}
+	return children
+}
+
+// validateChildPages records every ID in a "child_pages" section's Order that
+// isn't a page it lists, usually a typo that would otherwise be ignored.
+func validateChildPages(pages []Page, errs *BuildError) {
+	for _, page := range pages {
+		for _, s := range page.Sections {
+			data, ok := s.Data.(ChildPagesData)
+			if !ok || len(data.Order) == 0 {
+				continue
+			}
+			ids := make(map[string]bool)
+			for _, child := range childPages(page, pages) {
+				ids[child.PageID()] = true
+			}
+			for _, id := range data.Order {
+				if !ids[id] {
+					errs.Add(page.Path, s.TemplateName, fmt.Errorf("order: no page with id %q directly below this one", id))
+				}
+			}
+		}
+	}
+}
+
+func childCards(parent Page, pages []Page, order []string) []Card {
+	children := childPages(parent, pages)
+
+	rank := make(map[string]int, len(order))
+	for i, id := range order {
+		rank[id] = i + 1
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		ri, rj := rank[children[i].PageID()], rank[children[j].PageID()]
+		if ri != 0 || rj != 0 {
+			return ri != 0 && (rj == 0 || ri < rj)
+		}
+		return navLess(children[i].Nav.Order, children[j].Nav.Order)
+	})
+
+	cards := make([]Card, len(children))
+	for i, p := range children {
+		cards[i] = Card{Title: p.plainTitle(), Description: p.Description, URL: p.URL()}
+	}
+	return cards
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLandingPages(t *testing.T) {
+	site := Site{Name: "SA Tax Returns"}
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string // paths of the generated pages
+	}{
+		{
+			name:  "missing parent",
+			paths: []string{"index.html", "registrations/vat/index.html", "registrations/paye/index.html"},
+			want:  []string{"registrations/index.html"},
+		},
+		{
+			name:  "existing parent",
+			paths: []string{"submissions/index.html", "submissions/vat/index.html"},
+			want:  nil,
+		},
+		{
+			name:  "every missing ancestor",
+			paths: []string{"a/b/c/index.html"},
+			want:  []string{"a/b/index.html", "a/index.html"},
+		},
+		{
+			name:  "gap between existing pages",
+			paths: []string{"a/index.html", "a/b/c/index.html"},
+			want:  []string{"a/b/index.html"},
+		},
+		{
+			name:  "top-level pages need none",
+			paths: []string{"index.html", "about/index.html", "404.html"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []Page
+			for _, p := range tt.paths {
+				pages = append(pages, Page{Path: p})
+			}
+			var got []string
+			for _, page := range landingPages(pages, site) {
+				got = append(got, page.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("landing pages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLandingPageCopy(t *testing.T) {
+	landings := landingPages([]Page{{Path: "new-company/cipc/index.html"}}, Site{Name: "SA Tax Returns"})
+	if len(landings) != 1 {
+		t.Fatalf("got %d landing pages, want 1", len(landings))
+	}
+	page := landings[0]
+	if page.Title != "New Company | SA Tax Returns" || !page.Nav.Hide {
+		t.Errorf("landing page = %+v", page)
+	}
+	var templates []string
+	for _, s := range page.Sections {
+		templates = append(templates, s.TemplateName)
+	}
+	if want := []string{"hero", "breadcrumbs", "child_pages"}; !slices.Equal(templates, want) {
+		t.Errorf("sections = %q, want %q", templates, want)
+	}
+}
+
+func TestChildCards(t *testing.T) {
+	parent := Page{Path: "submissions/index.html"}
+	pages := []Page{
+		parent,
+		{Path: "submissions/vat/index.html", Title: "VAT", Nav: NavOptions{Order: 3}},
+		{Path: "submissions/paye/index.html", Title: "PAYE", Nav: NavOptions{Order: 2}},
+		{Path: "submissions/company-tax/index.html", Title: "Company Tax"},
+		{Path: "submissions/thank-you/index.html", Title: "Thanks", Sitemap: SitemapOptions{Exclude: true}},
+		{Path: "submissions/vat/thank-you/index.html", Title: "Deeper"},
+	}
+	tests := []struct {
+		order []string
+		want  string
+	}{
+		{nil, "PAYE, VAT, Company Tax"},
+		{[]string{"submissions/company-tax"}, "Company Tax, PAYE, VAT"},
+		{[]string{"submissions/vat", "submissions/company-tax"}, "VAT, Company Tax, PAYE"},
+	}
+	for _, tt := range tests {
+		var titles []string
+		for _, c := range childCards(parent, pages, tt.order) {
+			titles = append(titles, c.Title)
+		}
+		if got := strings.Join(titles, ", "); got != tt.want {
+			t.Errorf("order %q: cards %s, want %s", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestValidateChildPages(t *testing.T) {
+	pages := []Page{
+		{Path: "submissions/index.html", Sections: []Section{
+			{TemplateName: "child_pages", Data: ChildPagesData{Order: []string{"submissions/vat", "submissons/paye", "submissions/vat/thank-you"}}},
+		}},
+		{Path: "submissions/vat/index.html"},
+		{Path: "submissions/paye/index.html"},
+		{Path: "submissions/vat/thank-you/index.html"},
+	}
+	var errs BuildError
+	validateChildPages(pages, &errs)
+	var got []string
+	for _, p := range errs.Problems {
+		got = append(got, p.Err.Error())
+	}
+	want := []string{
+		`order: no page with id "submissons/paye" directly below this one`,
+		`order: no page with id "submissions/vat/thank-you" directly below this one`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
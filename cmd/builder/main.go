@@ -10,9 +10,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"website/internal/forms"
 )
 
+// cssFile is where Tailwind (npm run css) writes the compiled stylesheet. It
+// lives among the assets so every copy of them to build/ includes it.
+const cssFile = "assets/css/style.css"
+
 func main() {
 	devMode := flag.Bool("dev", false, "Run in development mode (watch and serve)")
 	flag.Parse()
@@ -22,20 +29,33 @@ func main() {
 		return
 	}
 
-	build()
+	if err := build(); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
 }
 
 func runDevMode() {
 	fmt.Println("Starting development server at http://localhost:8080")
 
-	// Initial build
-	build()
+	// Initial build; errors are reported but the server keeps running
+	status := &buildStatus{}
+	rebuildCSS()
+	if err := build(); err != nil {
+		log.Print(err)
+		status.Set(err)
+	}
 
 	// Start server
+	reload := newReloadBroker()
+	mux := http.NewServeMux()
+	mux.Handle(liveReloadPath, reload)
+	mux.Handle("/", newDevHandler("build", status))
+	server := &http.Server{Addr: ":8080", Handler: mux}
 	go func() {
-		fs := http.FileServer(http.Dir("build"))
-		http.Handle("/", fs)
-		log.Fatal(http.ListenAndServe(":8080", nil))
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
 	}()
 
 	// Polling watcher
@@ -43,38 +63,71 @@ func runDevMode() {
 	lastBuild := time.Now()
 
 	for range ticker.C {
-		if needsRebuild(lastBuild) {
-			fmt.Println("Change detected. Rebuilding...")
-			rebuildCSS() // Build CSS first
-			build()      // Then Build HTML
-			lastBuild = time.Now()
+		changed := changedSince(lastBuild)
+		if changed == 0 {
+			continue
 		}
-	}
-}
+		lastBuild = time.Now()
+
+		switch {
+		case changed&changeGo != 0:
+			// Content and builder code live in Go, so recompile and restart
+			fmt.Println("Builder source changed. Recompiling...")
+			if err := rebuildBuilder(devBinary()); err != nil {
+				fmt.Println("Error recompiling builder:", err)
+				continue
+			}
+			server.Close()
+			if err := restart(devBinary()); err != nil {
+				log.Fatal(err)
+			}
 
-func needsRebuild(lastBuild time.Time) bool {
-	// Check layouts and components
-	paths := []string{
-		"components/layouts/*.html",
-		"components/common/*.html",
-		"components/sections/*.html",
-	}
+		case changed&changePages != 0:
+			fmt.Println("Change detected. Rebuilding...")
+			rebuildCSS() // Build CSS first
+			// Then Build HTML; the browser reloads either way to show or clear the error overlay
+			err := build()
+			if err != nil {
+				log.Print(err)
+			}
+			status.Set(err)
+			reload.Publish(eventReload)
+
+		case changed&changeCSS != 0:
+			fmt.Println("Styles changed. Rebuilding CSS...")
+			cssErr := rebuildCSS()
+			// The compiled stylesheet is one of the assets
+			if err := copyAssets(); err != nil {
+				log.Print(err)
+			}
+			switch {
+			case changed&changeAssets != 0:
+				reload.Publish(eventReload)
+			case cssErr == nil:
+				reload.Publish(eventCSS)
+			}
 
-	for _, p := range paths {
-		files, _ := filepath.Glob(p)
-		for _, f := range files {
-			info, err := os.Stat(f)
-			if err == nil && info.ModTime().After(lastBuild) {
-				return true
+		case changed&changeAssets != 0:
+			fmt.Println("Assets changed. Copying...")
+			if err := copyAssets(); err != nil {
+				log.Print(err)
 			}
+			reload.Publish(eventReload)
 		}
 	}
-	return false
 }
 
-func rebuildCSS() {
+// rebuildBuilder compiles the builder into binary.
+func rebuildBuilder(binary string) error {
+	cmd := exec.Command("go", "build", "-o", binary, "./cmd/builder")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func rebuildCSS() error {
 	// Ensure directory exists
-	os.MkdirAll("build/assets/css", 0755)
+	os.MkdirAll(filepath.Dir(cssFile), 0755)
 	// Use npm run css to handle cross-platform binary paths
 	cmd := exec.Command("npm", "run", "css")
 	// On Windows, you might need "cmd", "/C", "npm", ... but normally exec checks PATH.
@@ -89,28 +142,30 @@ func rebuildCSS() {
 
 	if err := cmd.Run(); err != nil {
 		fmt.Println("Error rebuilding CSS:", err)
+		return err
 	}
+	return nil
 }
 
-func build() {
+// build renders every page and refreshes pages/ and build/. Pages are rendered
+// into a temporary directory first; if anything fails, all problems are
+// returned together and the previous outputs are left untouched.
+func build() error {
 	fmt.Println("Building site...")
 
 	// 1. Prepare target directories
 	pagesDir := "pages"
 	buildDir := "build"
 
-	// Ensure pages directory exists
-	if err := os.MkdirAll(pagesDir, 0755); err != nil {
-		log.Fatal(err)
-	}
-
 	// Ensure build directory exists
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// 2. Parse all templates
 	var tmpl *template.Template
+	var pageURLs map[string]string // page ID -> URL, filled once content is loaded
+	var site Site
 
 	funcMap := template.FuncMap{
 		"safe": func(s string) template.HTML {
@@ -127,81 +182,238 @@ func build() {
 			}
 			return template.HTML(buf.String()), nil
 		},
+		"site": func() Site {
+			// Brand and contact details for section templates: {{ site.Phone }}
+			return site
+		},
+		"honeypotField": func() string { return forms.HoneypotField },
+		"tokenField":    func() string { return forms.TokenField },
+		"consentField":  func() string { return forms.ConsentField },
+		"checked":       func() string { return forms.Checked },
+		"join":          strings.Join,
+		"year": func() int {
+			return time.Now().Year()
+		},
+		"url": func(id string) (string, error) {
+			// Links to another page by ID: {{ url "submissions/vat" }}
+			u, ok := pageURLs[id]
+			if !ok {
+				return "", fmt.Errorf("url: no page with id %q", id)
+			}
+			return u, nil
+		},
 	}
 
 	tmpl = template.New("").Funcs(funcMap)
 
 	// Glob patterns
-	layoutFiles, err := filepath.Glob("components/layouts/*.html")
-	if err != nil {
-		log.Fatal(err)
+	var allFiles []string
+	for _, pattern := range []string{
+		"components/layouts/*.html",
+		"components/common/*.html",
+		"components/sections/*.html",
+	} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		allFiles = append(allFiles, files...)
+	}
+
+	// Parse files one by one so every broken template is reported
+	var errs BuildError
+	for _, file := range allFiles {
+		if _, err := tmpl.ParseFiles(file); err != nil {
+			errs.Add("", filepath.Base(file), err)
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return err
 	}
-	commonFiles, err := filepath.Glob("components/common/*.html")
+
+	// 3. Get Content
+	var err error
+	if site, err = loadSite(); err != nil {
+		return err
+	}
+	pages, err := loadPages()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	sectionFiles, err := filepath.Glob("components/sections/*.html")
+	pages = append(pages, directoryIndex(pages, site)...)
+	taxonomies, err := loadTaxonomies()
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	pages = append(pages, taxonomyPages(pages, site, taxonomies, &errs)...)
+	pages = append(pages, prepareForms(pages, site)...)
+	pages = append(pages, landingPages(pages, site)...)
+
+	// Check every section before writing anything
+	pageURLs = indexPages(pages, &errs)
+	validatePages(pages, tmpl, &errs)
+	validateChildPages(pages, &errs)
+	validateTerms(pages, taxonomies, &errs)
+	validateSitemap(pages, &errs)
+	manifest := collectForms(pages, pageURLs, &errs)
+	if err := errs.Err(); err != nil {
+		return err
 	}
 
-	// Combine all files
-	allFiles := append(layoutFiles, commonFiles...)
-	allFiles = append(allFiles, sectionFiles...)
+	// 4. Generate Pages into a staging copy of 'pages/' (Source)
+	stagingDir, err := os.MkdirTemp(".", ".pages-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := os.Chmod(stagingDir, 0755); err != nil {
+		return err
+	}
 
-	if len(allFiles) > 0 {
-		_, err = tmpl.ParseFiles(allFiles...)
-		if err != nil {
-			log.Fatalf("Error parsing templates: %v", err)
-		}
+	// Content hashes drive sitemap <lastmod>
+	lastmod, err := updateLastmod(pages, time.Now())
+	if err != nil {
+		return err
 	}
 
-	// 3. Get Content
-	pages := GetSiteContent()
+	search := buildSearchIndex(pages)
+	searchJSON, err := search.Encode()
+	if err != nil {
+		return err
+	}
 
-	// 4. Generate Pages into 'pages/' directory (Source)
+	nav := buildNav(pages)
+	byURL := make(map[string]Page, len(pages))
+	for _, page := range pages {
+		byURL[page.URL()] = page
+	}
 	for _, page := range pages {
 		fmt.Printf("Generating content for %s...\n", page.Path)
 
-		// Determine path in pages directory
-		outputPath := filepath.Join(pagesDir, page.Path)
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-			log.Fatal(err)
+		layout := page.Layout
+		if layout == "" {
+			layout = "base.html"
 		}
 
-		f, err := os.Create(outputPath)
-		if err != nil {
-			log.Fatal(err)
+		view := PageView{Page: page, Site: site, Nav: activeNav(nav, page.URL())}
+		view.Breadcrumbs = breadcrumbTrail(page, byURL)
+		view.Sections = withBreadcrumbs(page.Sections, view.Breadcrumbs)
+		view.Sections = withChildPages(view.Sections, page, pages)
+		view.Sections = withPractitioners(view.Sections, pages, search)
+		if view.SocialImage() == "" && !page.Sitemap.Exclude {
+			fmt.Printf("Warning: %s has no social image (set Social.Image or a hero BackgroundImage)\n", page.Path)
 		}
 
+		var buf bytes.Buffer
 		// Add "Do Not Edit" warning
-		f.WriteString("<!-- \n  ⚠️  GENERATED FILE - DO NOT EDIT  ⚠️\n  This file is generated by the Go builder.\n  Edit content in 'cmd/builder/definitions.go' or templates in 'components/'.\n-->\n")
+		buf.WriteString("<!-- \n  ⚠️  GENERATED FILE - DO NOT EDIT  ⚠️\n  This file is generated by the Go builder.\n  Edit content in 'content/' or 'cmd/builder/definitions.go', or templates in 'components/'.\n-->\n")
 
-		err = tmpl.ExecuteTemplate(f, "base.html", page)
-		if err != nil {
-			log.Fatalf("Error executing template for %s: %v", page.Path, err)
+		if err := tmpl.ExecuteTemplate(&buf, layout, view); err != nil {
+			errs.Add(page.Path, layout, err)
+			continue
 		}
-		f.Close()
+		if err := checkStructuredData(buf.Bytes()); err != nil {
+			errs.Add(page.Path, layout, err)
+			continue
+		}
+
+		outputPath := filepath.Join(stagingDir, page.Path)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			errs.Add(page.Path, layout, err)
+			continue
+		}
+		if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+			errs.Add(page.Path, layout, err)
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	// Sitemap, robots.txt and the search index
+	if err := writeSitemap(stagingDir, site, pages, lastmod); err != nil {
+		return err
+	}
+	if err := writeSearchIndex(stagingDir, searchJSON); err != nil {
+		return err
 	}
 
-	// 5. Copy 'pages' content to 'build' (Distribution)
-	fmt.Println("Copying pages to build directory...")
-	copyDir(pagesDir, buildDir)
+	// Swap the staged pages into place
+	if err := replaceDir(stagingDir, pagesDir); err != nil {
+		return err
+	}
+	if err := saveLastmod(lastmod); err != nil {
+		return err
+	}
+	if err := forms.WriteManifest(formsManifest, manifest); err != nil {
+		return err
+	}
 
-	// 6. Copy 'assets' content to 'build/assets' (Images, JS, etc)
-	// We expect a root 'assets' folder
-	fmt.Println("Copying assets to build directory...")
-	copyDir("assets", filepath.Join(buildDir, "assets"))
+	// 5. Assemble 'build' (Distribution) from 'pages' and 'assets'
+	if _, err := os.Stat(cssFile); os.IsNotExist(err) {
+		fmt.Printf("Warning: %s is missing, so pages are unstyled; run npm run build, which compiles it first\n", cssFile)
+	}
+	fmt.Println("Copying pages and assets to build directory...")
+	if err := assembleBuild(pagesDir, "assets", buildDir); err != nil {
+		return err
+	}
 
 	fmt.Println("Done.")
+	return nil
 }
 
-func copyDir(src, dst string) {
+// assembleBuild fills buildDir with the pages and, below assets/, the assets
+// including the compiled stylesheet. It copies into a clean directory and
+// swaps that in, so files removed from either don't linger.
+func assembleBuild(pagesDir, assetsDir, buildDir string) error {
+	staging, err := os.MkdirTemp(filepath.Dir(buildDir), ".build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Chmod(staging, 0755); err != nil {
+		return err
+	}
+	if err := copyDir(pagesDir, staging); err != nil {
+		return err
+	}
+	if err := copyDir(assetsDir, filepath.Join(staging, "assets")); err != nil {
+		return err
+	}
+	return replaceDir(staging, buildDir)
+}
+
+// copyAssets copies the root 'assets' folder to 'build/assets'.
+func copyAssets() error {
+	fmt.Println("Copying assets to build directory...")
+	return copyDir("assets", filepath.Join("build", "assets"))
+}
+
+// replaceDir moves src to dst, replacing whatever was at dst.
+func replaceDir(src, dst string) error {
+	old := dst + ".old"
+	os.RemoveAll(old)
+	if err := os.Rename(dst, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		// Put the previous output back
+		os.Rename(old, dst)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// copyDir copies the files below src into dst. A missing src copies nothing.
+func copyDir(src, dst string) error {
 	// Walk source directory
 	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			// If source doesn't exist (e.g. no assets folder yet), just skip
-			return nil
+			if path == src && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
 		}
 
 		// Calculate destination path
@@ -224,6 +436,7 @@ func copyDir(src, dst string) {
 	})
 
 	if err != nil {
-		fmt.Printf("Warning copying %s to %s: %v\n", src, dst, err)
+		return fmt.Errorf("copying %s to %s: %w", src, dst, err)
 	}
+	return nil
 }
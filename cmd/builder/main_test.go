@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files below dir from a map of slash paths to contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFile returns the contents of a slash path below dir, or "" if it is
+// missing.
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func TestReplaceDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     map[string]string // nil: src is missing, so the rename fails
+		dst     map[string]string // nil: dst doesn't exist yet
+		wantErr bool
+		want    map[string]string // contents of dst afterwards
+	}{
+		{
+			name: "replaces dst",
+			src:  map[string]string{"index.html": "new"},
+			dst:  map[string]string{"index.html": "old", "stale.html": "old"},
+			want: map[string]string{"index.html": "new", "stale.html": ""},
+		},
+		{
+			name: "creates dst",
+			src:  map[string]string{"index.html": "new"},
+			want: map[string]string{"index.html": "new"},
+		},
+		{
+			name:    "restores dst on failure",
+			dst:     map[string]string{"index.html": "old"},
+			wantErr: true,
+			want:    map[string]string{"index.html": "old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src, dst := filepath.Join(dir, "staging"), filepath.Join(dir, "pages")
+			if tt.src != nil {
+				writeFiles(t, src, tt.src)
+			}
+			if tt.dst != nil {
+				writeFiles(t, dst, tt.dst)
+			}
+
+			err := replaceDir(src, dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("replaceDir error = %v, want error %v", err, tt.wantErr)
+			}
+			for name, want := range tt.want {
+				if got := readFile(t, dst, name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if _, err := os.Stat(dst + ".old"); !os.IsNotExist(err) {
+				t.Errorf("%s.old left behind", dst)
+			}
+		})
+	}
+}
+
+func TestAssembleBuildKeepsCompiledCSS(t *testing.T) {
+	dir := t.TempDir()
+	pages, assets, build := filepath.Join(dir, "pages"), filepath.Join(dir, "assets"), filepath.Join(dir, "build")
+	writeFiles(t, pages, map[string]string{"index.html": "home"})
+	writeFiles(t, assets, map[string]string{"css/style.css": "/* compiled */", "images/logo.png": "png"})
+	writeFiles(t, build, map[string]string{"assets/css/style.css": "/* stale */", "removed/index.html": "gone"})
+
+	if err := assembleBuild(pages, assets, build); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"index.html":             "home",
+		"assets/css/style.css":   "/* compiled */",
+		"assets/images/logo.png": "png",
+		"removed/index.html":     "",
+	} {
+		if got := readFile(t, build, name); got != want {
+			t.Errorf("build/%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// The css script must write where the builder copies the stylesheet from.
+func TestCSSScriptWritesCSSFile(t *testing.T) {
+	raw, err := os.ReadFile("../../package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(raw, &pkg); err != nil {
+		t.Fatal(err)
+	}
+	if script := pkg.Scripts["css"]; !strings.Contains(script, "-o ./"+cssFile+" ") {
+		t.Errorf("npm run css = %q, want it to write %s", script, cssFile)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// MarkdownData is the rendered body of a content/*.md page.
+type MarkdownData struct {
+	HTML template.HTML
+}
+
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// loadMarkdownFile reads a page written as YAML front matter followed by a
+// Markdown body. The front matter uses the same keys as a page data file, so
+// it can declare sections (e.g. a hero) that are rendered before the body.
+//
+//	---
+//	title: Personal Tax Returns
+//	path: submissions/personal-tax/index.html
+//	---
+//	Body in **Markdown**.
+func loadMarkdownFile(path string) (Page, error) {
+	var page Page
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return page, err
+	}
+
+	front, body, err := splitFrontMatter(string(raw))
+	if err != nil {
+		return page, fmt.Errorf("%s: %w", path, err)
+	}
+	// The newline stands for the opening "---", so lines match the file
+	if err := decodePage([]byte("\n"+front), &page); err != nil {
+		return page, fmt.Errorf("%s: front matter: %w", path, err)
+	}
+	if page.Path == "" {
+		return page, fmt.Errorf("%s: missing path", path)
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(body), &buf); err != nil {
+		return page, fmt.Errorf("%s: %w", path, err)
+	}
+	page.Sections = append(page.Sections, Section{
+		TemplateName: "markdown",
+		Data:         MarkdownData{HTML: template.HTML(buf.String())},
+	})
+	page.source = path
+	return page, nil
+}
+
+// splitFrontMatter separates the "---" delimited header from the body.
+func splitFrontMatter(s string) (front, body string, err error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if !strings.HasPrefix(s, "---\n") {
+		return "", "", fmt.Errorf("missing front matter")
+	}
+	s = s[len("---\n"):]
+
+	end := strings.Index(s, "\n---\n")
+	if end < 0 {
+		if !strings.HasSuffix(s, "\n---") {
+			return "", "", fmt.Errorf("unterminated front matter")
+		}
+		return strings.TrimSuffix(s, "\n---"), "", nil
+	}
+	return s[:end], s[end+len("\n---\n"):], nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import "sort"
+
+// NavOptions places a page in the generated site navigation.
+type NavOptions struct {
+	Group string `yaml:"group"` // dropdown the page belongs to, e.g. "Submissions"; empty for a top-level link
+	Label string `yaml:"label"` // link text, defaults to the page's short title
+	Order int    `yaml:"order"` // position among its siblings; unset (0) sorts last
+	Hide  bool   `yaml:"hide"`  // leave the page out of the navigation
+}
+
+// NavItem is a link, or a group of links, as seen by the header and footer.
+type NavItem struct {
+	Label    string
+	URL      string
+	Active   bool // this is the current page, or the group containing it
+	Children []NavItem
+
+	order int
+}
+
+// NavLabel is the page's name in navigation and breadcrumbs.
+func (p Page) NavLabel() string {
+	if p.Nav.Label != "" {
+		return p.Nav.Label
+	}
+	return p.ShortTitle()
+}
+
+// buildNav turns the pages' nav options into the site navigation: top-level
+// links and groups, each sorted by Order. A group sits where its
+// lowest-ordered page would, and links to the page for its pages' directory
+// (e.g. /registrations/) when there is one.
+func buildNav(pages []Page) []NavItem {
+	var items []NavItem
+	groups := make(map[string]int) // group label -> index in items
+	byURL := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		byURL[page.URL()] = true
+	}
+
+	for _, page := range pages {
+		opts := page.Nav
+		if opts.Hide {
+			continue
+		}
+		link := NavItem{Label: page.NavLabel(), URL: page.URL(), order: opts.Order}
+
+		if opts.Group == "" {
+			items = append(items, link)
+			continue
+		}
+		i, ok := groups[opts.Group]
+		if !ok {
+			i = len(items)
+			groups[opts.Group] = i
+			group := NavItem{Label: opts.Group, order: opts.Order}
+			if dir := parentURL(page.URL()); dir != "/" && byURL[dir] {
+				group.URL = dir
+			}
+			items = append(items, group)
+		}
+		items[i].Children = append(items[i].Children, link)
+		if navLess(link.order, items[i].order) {
+			items[i].order = link.order
+		}
+	}
+
+	sortNav(items)
+	for i := range items {
+		sortNav(items[i].Children)
+	}
+	return items
+}
+
+func sortNav(items []NavItem) {
+	sort.SliceStable(items, func(i, j int) bool { return navLess(items[i].order, items[j].order) })
+}
+
+func navLess(a, b int) bool {
+	if a == 0 || b == 0 {
+		return a != 0 && b == 0
+	}
+	return a < b
+}
+
+// activeNav returns a copy of nav with Active set on the link to url and on
+// the group that contains it.
+func activeNav(nav []NavItem, url string) []NavItem {
+	out := make([]NavItem, len(nav))
+	for i, item := range nav {
+		out[i] = item
+		if item.Children != nil {
+			out[i].Children = activeNav(item.Children, url)
+			out[i].Active = item.URL == url
+			for _, child := range out[i].Children {
+				if child.Active {
+					out[i].Active = true
+				}
+			}
+			continue
+		}
+		out[i].Active = item.URL == url
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// navString writes nav compactly: "Label /url/" per link, children in
+// brackets and a "*" after active items.
+func navString(nav []NavItem) string {
+	var parts []string
+	for _, item := range nav {
+		s := strings.TrimSpace(item.Label + " " + item.URL)
+		if item.Active {
+			s += "*"
+		}
+		if item.Children != nil {
+			s += " [" + navString(item.Children) + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestBuildNav(t *testing.T) {
+	page := func(path, title string, nav NavOptions) Page {
+		return Page{Path: path, Title: title + " | SA Tax Returns", Nav: nav}
+	}
+	tests := []struct {
+		name  string
+		pages []Page
+		want  string
+	}{
+		{
+			name: "sorted by order, unset last",
+			pages: []Page{
+				page("contact/index.html", "Contact", NavOptions{}),
+				page("about/index.html", "About", NavOptions{Order: 2}),
+				page("index.html", "Home", NavOptions{Order: 1}),
+			},
+			want: "Home /, About /about/, Contact /contact/",
+		},
+		{
+			name: "hidden pages and labels",
+			pages: []Page{
+				page("index.html", "Home", NavOptions{Order: 1}),
+				page("privacy/index.html", "Privacy", NavOptions{Hide: true}),
+				page("about/index.html", "About Our Team", NavOptions{Label: "About", Order: 2}),
+			},
+			want: "Home /, About /about/",
+		},
+		{
+			name: "group sits at its lowest order and links to its directory",
+			pages: []Page{
+				page("index.html", "Home", NavOptions{Order: 1}),
+				page("contact/index.html", "Contact", NavOptions{Order: 30}),
+				page("registrations/index.html", "Registrations", NavOptions{Hide: true}),
+				page("registrations/vat/index.html", "VAT", NavOptions{Group: "Registrations", Order: 22}),
+				page("registrations/paye/index.html", "PAYE", NavOptions{Group: "Registrations", Order: 21}),
+			},
+			want: "Home /, Registrations /registrations/ [PAYE /registrations/paye/, VAT /registrations/vat/], Contact /contact/",
+		},
+		{
+			name: "group without a directory page",
+			pages: []Page{
+				page("submissions/vat/index.html", "VAT", NavOptions{Group: "Submissions"}),
+			},
+			want: "Submissions [VAT /submissions/vat/]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := navString(buildNav(tt.pages)); got != tt.want {
+				t.Errorf("buildNav:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveNav(t *testing.T) {
+	nav := buildNav([]Page{
+		{Path: "index.html", Title: "Home", Nav: NavOptions{Order: 1}},
+		{Path: "submissions/vat/index.html", Title: "VAT", Nav: NavOptions{Group: "Submissions", Order: 2}},
+	})
+	for url, want := range map[string]string{
+		"/":                 "Home /*, Submissions [VAT /submissions/vat/]",
+		"/submissions/vat/": "Home /, Submissions* [VAT /submissions/vat/*]",
+		"/about/":           "Home /, Submissions [VAT /submissions/vat/]",
+	} {
+		if got := navString(activeNav(nav, url)); got != want {
+			t.Errorf("activeNav(%s):\n got %s\nwant %s", url, got, want)
+		}
+	}
+	if nav[0].Active {
+		t.Error("activeNav changed the shared nav")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// buildStatus remembers the outcome of the latest build for the dev server.
+type buildStatus struct {
+	mu  sync.RWMutex
+	err error
+}
+
+func (s *buildStatus) Set(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func (s *buildStatus) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// OverlayEntry is one problem as shown in the browser.
+type OverlayEntry struct {
+	Page    string
+	File    string // template file under components/, if known
+	Line    int
+	Column  int
+	Message string
+}
+
+// templateErrorPos matches the position prefix html/template and
+// text/template put on parse and exec errors, e.g. "template: hero.html:24:"
+// or "html/template:hero.html:12:5:".
+var templateErrorPos = regexp.MustCompile(`(?:html/)?template: ?([^:\s]+):(\d+)(?::(\d+))?: `)
+
+// overlayEntries turns a build error into entries pointing at the template
+// that actually failed. Exec errors from nested sections wrap each other, so
+// the innermost position is the useful one.
+func overlayEntries(err error) []OverlayEntry {
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		return []OverlayEntry{parseTemplateError("", "", err)}
+	}
+	entries := make([]OverlayEntry, len(buildErr.Problems))
+	for i, p := range buildErr.Problems {
+		entries[i] = parseTemplateError(p.Page, p.Template, p.Err)
+	}
+	return entries
+}
+
+func parseTemplateError(page, tmpl string, err error) OverlayEntry {
+	entry := OverlayEntry{Page: page, Message: err.Error()}
+	if tmpl != "" {
+		entry.File = templateFile(tmpl)
+	}
+
+	msg := err.Error()
+	matches := templateErrorPos.FindAllStringSubmatchIndex(msg, -1)
+	if len(matches) == 0 {
+		return entry
+	}
+	m := matches[len(matches)-1]
+	entry.File = templateFile(msg[m[2]:m[3]])
+	entry.Line, _ = strconv.Atoi(msg[m[4]:m[5]])
+	if m[6] >= 0 {
+		entry.Column, _ = strconv.Atoi(msg[m[6]:m[7]])
+	}
+	entry.Message = msg[m[1]:]
+	return entry
+}
+
+// templateFile finds the components/ file a template was parsed from.
+func templateFile(name string) string {
+	matches, _ := filepath.Glob(filepath.Join("components", "*", name))
+	if len(matches) == 0 {
+		return name
+	}
+	return filepath.ToSlash(matches[0])
+}
+
+var overlayTemplate = template.Must(template.New("overlay").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Build failed</title>
+    <style>
+        body { margin: 0; padding: 48px; background: #1a1a1a; color: #f3f4f6; font: 15px/1.5 ui-sans-serif, system-ui, sans-serif; }
+        h1 { color: #ff4c4c; font-size: 24px; margin: 0 0 8px; }
+        p { color: #9ca3af; margin: 0 0 32px; }
+        li { list-style: none; background: #262626; border-left: 4px solid #ff4c4c; padding: 16px 20px; margin-bottom: 16px; border-radius: 4px; }
+        .where { color: #fca5a5; font-family: ui-monospace, monospace; }
+        .page { color: #9ca3af; font-size: 13px; }
+        pre { white-space: pre-wrap; margin: 8px 0 0; font-family: ui-monospace, monospace; }
+    </style>
+</head>
+<body>
+    <h1>Build failed</h1>
+    <p>Showing the latest build error. This page clears itself once the build succeeds.</p>
+    <ul>
+        {{ range . }}
+        <li>
+            {{ if .File }}<div class="where">{{ .File }}{{ if .Line }}:{{ .Line }}{{ if .Column }}:{{ .Column }}{{ end }}{{ end }}</div>{{ end }}
+            {{ if .Page }}<div class="page">while building {{ .Page }}</div>{{ end }}
+            <pre>{{ .Message }}</pre>
+        </li>
+        {{ end }}
+    </ul>
+</body>
+</html>
+`))
+
+// serveOverlay writes the error page for err, with the live reload client so
+// the browser recovers by itself.
+func serveOverlay(w http.ResponseWriter, err error) {
+	var buf bytes.Buffer
+	if execErr := overlayTemplate.Execute(&buf, overlayEntries(err)); execErr != nil {
+		buf.WriteString(execErr.Error())
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write(injectScript(buf.Bytes(), liveReloadScript))
+}
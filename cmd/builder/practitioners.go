@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"website/internal/directory"
+	"website/internal/forms"
+	"website/internal/reviews"
+)
+
+// directoryURL is where the practitioner directory lives.
+const directoryURL = "/practitioners/"
+
+// getListedURL is where practitioners apply to be listed.
+const getListedURL = "/get-listed/"
+
+// reviewReceivedID is the page shown after a review is posted.
+const reviewReceivedID = "review-received"
+
+// directoryImage is the hero background of generated directory pages.
+const directoryImage = "/assets/images/hero_background_capetown.png"
+
+// PractitionerData is the data for the "practitioner_profile" section: a
+// practitioner's details, services, contact information and published
+// reviews, newest first.
+type PractitionerData struct {
+	directory.Practitioner `yaml:",inline"`
+	Reviews                []reviews.Review `yaml:"-"`
+}
+
+// Rating is the average of the practitioner's reviews.
+func (p PractitionerData) Rating() reviews.Summary {
+	return reviews.Summarize(p.Reviews)
+}
+
+// PractitionerListData is the data for the "practitioner_list" section: a
+// card for every practitioner in the directory. The builder fills Items.
+type PractitionerListData struct {
+	Title string             `yaml:"title"`
+	Intro string             `yaml:"intro"`
+	Items []PractitionerCard `yaml:"-"`
+}
+
+// DirectoryData is the data for the "directory" section: every practitioner's
+// card with city, service and language filters that query the search index in
+// the browser. Without JavaScript it shows every card. The builder fills all
+// but Title and Intro.
+type DirectoryData struct {
+	Title     string             `yaml:"title"`
+	Intro     string             `yaml:"intro"`
+	Index     string             `yaml:"-"` // URL of the search index
+	Items     []PractitionerCard `yaml:"-"`
+	Areas     []string           `yaml:"-"`
+	Services  []string           `yaml:"-"`
+	Languages []string           `yaml:"-"`
+}
+
+// DirectoryFilter is one of the directory's drop-downs. Name is also the
+// query parameter, so filtered views can be linked to, e.g. ?city=Durban.
+type DirectoryFilter struct {
+	Name    string
+	Label   string
+	All     string // the option that doesn't filter
+	Options []string
+}
+
+// Filters lists the drop-downs in display order.
+func (d DirectoryData) Filters() []DirectoryFilter {
+	return []DirectoryFilter{
+		{Name: "city", Label: "City", All: "All cities", Options: d.Areas},
+		{Name: "service", Label: "Service", All: "All services", Options: d.Services},
+		{Name: "language", Label: "Language", All: "All languages", Options: d.Languages},
+	}
+}
+
+// PractitionerCard links to a practitioner's profile.
+type PractitionerCard struct {
+	directory.Practitioner
+	URL    string
+	Rating reviews.Summary
+}
+
+// loadPractitionerPages generates a profile page for every practitioner in
+// the directory: their details and reviews, a form that emails them
+// enquiries and a form to review them. The review forms share a page
+// confirming the review was received.
+func loadPractitionerPages() ([]Page, error) {
+	list, err := directory.Load(directory.Dir)
+	if err != nil {
+		return nil, err
+	}
+	published, err := reviews.Load(reviews.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	pages := make([]Page, 0, len(list)+1)
+	for _, p := range list {
+		pages = append(pages, profilePage(p, published[p.Slug]))
+	}
+	return append(pages, reviewReceivedPage()), nil
+}
+
+func profilePage(p directory.Practitioner, list []reviews.Review) Page {
+	enquiry := FormData{
+		Title:      "Contact " + p.FirstName(),
+		Intro:      fmt.Sprintf("Send %s a message and they'll get back to you directly.", p.FirstName()),
+		ButtonText: "Send Enquiry",
+		Fields: []forms.Field{
+			{Name: "name", Label: "Full name", Required: true},
+			{Name: "email", Label: "Email", Type: forms.TypeEmail, Required: true},
+			{Name: "phone", Label: "Phone", Type: forms.TypePhone},
+			{Name: "service", Label: "Service needed", Type: forms.TypeSelect, Options: p.Services},
+			{Name: "message", Label: "Message", Type: forms.TypeTextarea, Required: true},
+		},
+	}
+	if p.Contact.Email != "" {
+		enquiry.Notify = []string{p.Contact.Email}
+	}
+
+	return Page{
+		Title:       fmt.Sprintf("%s, %s | Tax Practitioner in %s", p.Name, p.Practice, p.Areas[0]),
+		Description: profileDescription(p),
+		Path:        strings.TrimPrefix(directoryURL, "/") + p.Slug + "/index.html",
+		Nav:         NavOptions{Hide: true},
+		Social:      SocialMeta{Image: p.Photo, Type: "profile"},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{
+				Title:      p.Name,
+				Subtitle:   fmt.Sprintf("%s · %s registered tax practitioner", p.Practice, p.Registration.Body),
+				PrimaryBtn: "Contact " + p.FirstName(),
+			}},
+			{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+			{TemplateName: "practitioner_profile", Data: PractitionerData{Practitioner: p, Reviews: list}},
+			{TemplateName: "form", Data: enquiry},
+			{TemplateName: "form", Data: FormData{
+				ID:          "review-" + p.Slug,
+				Title:       "Review " + p.FirstName(),
+				Intro:       "Reviews are checked by our team before they appear.",
+				ButtonText:  "Submit Review",
+				SuccessPage: reviewReceivedID,
+				Kind:        forms.KindReview,
+				Subject:     p.Slug,
+				Fields:      reviews.FormFields(p.FirstName()),
+			}},
+		},
+		source: directory.Dir + "/" + p.Slug + ".yaml",
+	}
+}
+
+func reviewReceivedPage() Page {
+	return Page{
+		ID:          reviewReceivedID,
+		Title:       "Thanks for Your Review",
+		Description: "Your review has been received and will appear once it has been checked.",
+		Path:        strings.TrimPrefix(directoryURL, "/") + reviewReceivedID + "/index.html",
+		Nav:         NavOptions{Hide: true},
+		Sitemap:     SitemapOptions{Exclude: true},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{
+				Title:       "Thanks for Your Review",
+				Subtitle:    "Our team checks every review before it appears on the practitioner's profile, usually within a few working days.",
+				PrimaryBtn:  "Back to the Directory",
+				PrimaryLink: directoryURL,
+			}},
+		},
+		source: "generated review confirmation page",
+	}
+}
+
+// profileDescription summarises a practitioner for search results.
+func profileDescription(p directory.Practitioner) string {
+	return fmt.Sprintf("%s of %s is a %s registered tax practitioner serving %s. Services: %s.",
+		p.Name, p.Practice, p.Registration.Body, strings.Join(p.Areas, ", "), strings.Join(p.Services, ", "))
+}
+
+// directoryIndex generates the directory's index page, unless a page already
+// lives there. To change its copy, add a page at practitioners/index.html
+// with a "directory" or "practitioner_list" section.
+func directoryIndex(pages []Page, site Site) []Page {
+	for _, page := range pages {
+		if page.URL() == directoryURL {
+			return nil
+		}
+	}
+	return []Page{{
+		Title:       "Find a Tax Practitioner | " + site.Name,
+		Description: fmt.Sprintf("Browse verified SAIT and SAICA registered tax practitioners listed on %s.", site.Name),
+		Path:        strings.TrimPrefix(directoryURL, "/") + "index.html",
+		Nav:         NavOptions{Label: "Find a Practitioner", Order: 10},
+		Sitemap:     SitemapOptions{Priority: 0.9, ChangeFreq: "weekly"},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{
+				Title:           "Find a Tax Practitioner",
+				Subtitle:        "We check every practitioner's SAIT or SAICA registration before listing them.",
+				BackgroundImage: directoryImage,
+			}},
+			{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+			{TemplateName: "directory", Data: DirectoryData{}},
+		},
+		source: "generated directory index",
+	}}
+}
+
+// withPractitioners returns the page's sections with a card for every
+// profile page filled into any "practitioner_list" or "directory" section,
+// and the search index's filters into any "directory" section.
+func withPractitioners(sections []Section, pages []Page, index searchIndex) []Section {
+	out := make([]Section, len(sections))
+	for i, s := range sections {
+		out[i] = s
+		switch data := s.Data.(type) {
+		case PractitionerListData:
+			if len(data.Items) == 0 {
+				data.Items = practitionerCards(pages)
+				out[i].Data = data
+			}
+		case DirectoryData:
+			data.Index = index.URL
+			data.Items = practitionerCards(pages)
+			data.Areas = index.Areas
+			data.Services = index.Services
+			data.Languages = index.Languages
+			out[i].Data = data
+		}
+	}
+	return out
+}
+
+func practitionerCards(pages []Page) []PractitionerCard {
+	var cards []PractitionerCard
+	for _, page := range pages {
+		for _, s := range page.Sections {
+			if data, ok := s.Data.(PractitionerData); ok {
+				cards = append(cards, PractitionerCard{Practitioner: data.Practitioner, URL: page.URL(), Rating: data.Rating()})
+			}
+		}
+	}
+	return cards
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restart replaces the running process with binary, keeping the same
+// arguments and environment.
+func restart(binary string) error {
+	return syscall.Exec(binary, append([]string{binary}, os.Args[1:]...), os.Environ())
+}
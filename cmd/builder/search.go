@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// searchIndexFile is the search index, written next to the pages. The
+// "directory" section filters practitioners with it in the browser, so the
+// site stays fully static.
+const searchIndexFile = "search-index.json"
+
+// searchIndex is kept compact: every service, area and language is listed
+// once, and practitioners refer to them by position.
+type searchIndex struct {
+	URL           string               `json:"-"` // set once encoded, with a version to bust caches
+	Services      []string             `json:"services"`
+	Areas         []string             `json:"areas"`
+	Languages     []string             `json:"languages"`
+	Practitioners []searchPractitioner `json:"practitioners"`
+	Pages         []searchPage         `json:"pages"`
+}
+
+type searchPractitioner struct {
+	Name      string `json:"n"`
+	Practice  string `json:"p"`
+	URL       string `json:"u"`
+	Services  []int  `json:"s"`
+	Areas     []int  `json:"a"`
+	Languages []int  `json:"l"`
+}
+
+// searchPage is any other page in the sitemap, for text searches.
+type searchPage struct {
+	Title       string `json:"t"`
+	Description string `json:"d"`
+	URL         string `json:"u"`
+}
+
+// buildSearchIndex indexes every practitioner profile and every page in the
+// sitemap.
+func buildSearchIndex(pages []Page) searchIndex {
+	var index searchIndex
+	cards := practitionerCards(pages)
+	for _, c := range cards {
+		index.Services = append(index.Services, c.Services...)
+		index.Areas = append(index.Areas, c.Areas...)
+		index.Languages = append(index.Languages, c.Languages...)
+	}
+	index.Services = uniqueSorted(index.Services)
+	index.Areas = uniqueSorted(index.Areas)
+	index.Languages = uniqueSorted(index.Languages)
+
+	index.Practitioners = []searchPractitioner{}
+	for _, c := range cards {
+		index.Practitioners = append(index.Practitioners, searchPractitioner{
+			Name:      c.Name,
+			Practice:  c.Practice,
+			URL:       c.URL,
+			Services:  positions(index.Services, c.Services),
+			Areas:     positions(index.Areas, c.Areas),
+			Languages: positions(index.Languages, c.Languages),
+		})
+	}
+
+	index.Pages = []searchPage{}
+	for _, page := range pages {
+		if page.Sitemap.Exclude || isProfile(page) {
+			continue
+		}
+		index.Pages = append(index.Pages, searchPage{Title: page.ShortTitle(), Description: page.Description, URL: page.URL()})
+	}
+	sort.Slice(index.Pages, func(i, j int) bool { return index.Pages[i].URL < index.Pages[j].URL })
+	return index
+}
+
+// Encode returns the index as JSON and sets its URL, versioned by a short
+// hash of the JSON so browsers fetch it again when it changes.
+func (idx *searchIndex) Encode() ([]byte, error) {
+	raw, err := json.Marshal(idx)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	idx.URL = "/" + searchIndexFile + "?v=" + hex.EncodeToString(sum[:4])
+	return raw, nil
+}
+
+func writeSearchIndex(dir string, raw []byte) error {
+	return os.WriteFile(filepath.Join(dir, searchIndexFile), raw, 0644)
+}
+
+// isProfile reports whether page is a practitioner profile.
+func isProfile(page Page) bool {
+	return slices.ContainsFunc(page.Sections, func(s Section) bool {
+		_, ok := s.Data.(PractitionerData)
+		return ok
+	})
+}
+
+// uniqueSorted sorts list ignoring case and drops values that differ only by
+// case, keeping the first spelling.
+func uniqueSorted(list []string) []string {
+	out := slices.Clone(list)
+	slices.SortStableFunc(out, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+	return slices.CompactFunc(out, strings.EqualFold)
+}
+
+// positions returns where each of values sits in terms, ignoring case like
+// uniqueSorted.
+func positions(terms, values []string) []int {
+	out := make([]int, 0, len(values))
+	for _, v := range values {
+		i := slices.IndexFunc(terms, func(t string) bool { return strings.EqualFold(t, v) })
+		if i >= 0 && !slices.Contains(out, i) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUniqueSorted(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"sorts ignoring case", []string{"durban", "Cape Town", "Bloemfontein"}, []string{"Bloemfontein", "Cape Town", "durban"}},
+		{"drops exact duplicates", []string{"VAT", "PAYE", "VAT"}, []string{"PAYE", "VAT"}},
+		{"drops case variants, keeping the first", []string{"Cape Town", "Durban", "cape town", "CAPE TOWN"}, []string{"Cape Town", "Durban"}},
+		{"non-adjacent duplicates", []string{"isiXhosa", "English", "Afrikaans", "english", "IsiXhosa"}, []string{"Afrikaans", "English", "isiXhosa"}},
+		{"empty", nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uniqueSorted(tt.in)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("uniqueSorted(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionsIgnoreCase(t *testing.T) {
+	terms := uniqueSorted([]string{"Cape Town", "Durban", "cape town"})
+	got := positions(terms, []string{"cape town", "Durban", "CAPE TOWN", "Pretoria"})
+	if want := []int{0, 1}; !slices.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
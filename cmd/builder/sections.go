@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectionSpec describes what a section template expects as its Data.
+type SectionSpec struct {
+	Type     reflect.Type
+	Required []string // fields of Type that must not be empty
+}
+
+// sectionRegistry maps every section template name to its data schema.
+// A section template in components/sections/ must be registered here before
+// pages can use it.
+var sectionRegistry = map[string]SectionSpec{
+	"hero":         {Type: reflect.TypeOf(HeroData{}), Required: []string{"Title"}},
+	"text_block":   {Type: reflect.TypeOf(TextBlockData{}), Required: []string{"Heading", "Paragraphs"}},
+	"features":     {Type: reflect.TypeOf(FeaturesData{}), Required: []string{"Title", "Items"}},
+	"contact_form": {Type: reflect.TypeOf(ContactFormData{}), Required: []string{"Title", "ButtonText"}},
+	"form":         {Type: reflect.TypeOf(FormData{}), Required: []string{"Title", "ButtonText", "Fields"}},
+	"markdown":     {Type: reflect.TypeOf(MarkdownData{}), Required: []string{"HTML"}},
+	"breadcrumbs":  {Type: reflect.TypeOf(BreadcrumbsData{})},
+	"child_pages":  {Type: reflect.TypeOf(ChildPagesData{})},
+
+	"privacy_version": {Type: reflect.TypeOf(PrivacyVersionData{})},
+
+	"practitioner_profile": {Type: reflect.TypeOf(PractitionerData{}), Required: []string{"Name", "Practice"}},
+	"practitioner_list":    {Type: reflect.TypeOf(PractitionerListData{})},
+	"directory":            {Type: reflect.TypeOf(DirectoryData{})},
+}
+
+// validatePages checks every section of every page against the registry and
+// the parsed template set, recording each problem in errs so they can all be
+// fixed in one pass.
+func validatePages(pages []Page, tmpl *template.Template, errs *BuildError) {
+	unknown := func(page Page, key *yaml.Node) error {
+		return fmt.Errorf("%s:%d: unknown key %q", page.source, key.Line, key.Value)
+	}
+	for _, page := range pages {
+		for _, key := range page.unknown {
+			errs.Add(page.Path, "", unknown(page, key))
+		}
+		for i, s := range page.Sections {
+			for _, key := range s.unknown {
+				errs.Add(page.Path, s.TemplateName, fmt.Errorf("section %d: %w", i+1, unknown(page, key)))
+			}
+			for _, msg := range validateSection(s, tmpl) {
+				errs.Add(page.Path, s.TemplateName, fmt.Errorf("section %d: %s", i+1, msg))
+			}
+		}
+	}
+}
+
+func validateSection(s Section, tmpl *template.Template) []string {
+	spec, ok := sectionRegistry[s.TemplateName]
+	if !ok {
+		return []string{"unknown section template"}
+	}
+
+	var problems []string
+	if tmpl.Lookup(s.TemplateName) == nil {
+		problems = append(problems, "no template defined in components/sections/")
+	}
+
+	got := reflect.TypeOf(s.Data)
+	if got != spec.Type {
+		return append(problems, fmt.Sprintf("data is %v, want %v", got, spec.Type))
+	}
+
+	v := reflect.ValueOf(s.Data)
+	for _, name := range spec.Required {
+		if f := v.FieldByName(name); !f.IsValid() || f.IsZero() || (f.Kind() == reflect.Slice && f.Len() == 0) {
+			problems = append(problems, "missing required field "+name)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// siteFile holds the site-wide settings. Anything it leaves out keeps the
+// value from defaultSite.
+const siteFile = "data/site.yaml"
+
+// Site holds the branding and business details shared by every page.
+// Layouts, header and footer see it as .Site; section templates can call
+// {{ site }}.
+type Site struct {
+	BaseURL  string `yaml:"base_url"`  // e.g. "https://www.example.co.za", no trailing slash
+	FormsURL string `yaml:"forms_url"` // where cmd/formserver is reachable; empty when proxied on the same host
+
+	// Brand
+	Name           string `yaml:"name"`
+	Tagline        string `yaml:"tagline"`
+	Logo           string `yaml:"logo"` // image under /assets; the name is shown as text if empty
+	CopyrightOwner string `yaml:"copyright_owner"`
+
+	// Contact details
+	Phone   string  `yaml:"phone"`
+	Email   string  `yaml:"email"`
+	Address Address `yaml:"address"`
+
+	AreaServed    []string       `yaml:"area_served"`
+	Registrations []Registration `yaml:"registrations"`
+	Social        SocialLinks    `yaml:"social"`
+
+	Privacy Privacy `yaml:"privacy"`
+}
+
+// Privacy is the site's privacy notice. When it has a version, every form
+// asks visitors to accept it, and the form server records the version with
+// each submission (POPIA). Bump the version whenever the notice changes.
+type Privacy struct {
+	Notice  string `yaml:"notice"`  // page ID of the privacy notice
+	Version string `yaml:"version"` // e.g. "2026-10-18"
+}
+
+// PrivacyVersionData is the data for the "privacy_version" section, which
+// states the version of the notice from the site settings so the page and the
+// version recorded with submissions can't drift apart.
+type PrivacyVersionData struct{}
+
+// Registration is a professional or company registration shown in the footer,
+// e.g. {Label: "SAIT Practice No.", Number: "..."}.
+type Registration struct {
+	Label  string `yaml:"label"`
+	Number string `yaml:"number"`
+}
+
+// SocialLinks are the brand's profiles. Empty links are not rendered.
+type SocialLinks struct {
+	Facebook  string `yaml:"facebook"`
+	LinkedIn  string `yaml:"linkedin"`
+	X         string `yaml:"x"`
+	Instagram string `yaml:"instagram"`
+}
+
+// Any reports whether at least one social link is set.
+func (l SocialLinks) Any() bool {
+	return l != (SocialLinks{})
+}
+
+// Address is a physical street address.
+type Address struct {
+	Street     string `yaml:"street"`
+	Locality   string `yaml:"locality"` // city or town
+	Region     string `yaml:"region"`   // province
+	PostalCode string `yaml:"postal_code"`
+	Country    string `yaml:"country"` // ISO 3166 code, e.g. "ZA"
+}
+
+func defaultSite() Site {
+	return Site{
+		BaseURL:        "http://localhost:8080",
+		Name:           "SA Tax Returns",
+		Tagline:        "Connecting South African taxpayers with verified tax practitioners.",
+		CopyrightOwner: "SA Tax Returns",
+		Address:        Address{Country: "ZA"},
+	}
+}
+
+// loadSite reads siteFile over the defaults. A missing file is not an error.
+func loadSite() (Site, error) {
+	site := defaultSite()
+	raw, err := os.ReadFile(siteFile)
+	if os.IsNotExist(err) {
+		return site, nil
+	}
+	if err != nil {
+		return site, err
+	}
+	if err := yaml.Unmarshal(raw, &site); err != nil {
+		return site, fmt.Errorf("%s: %w", siteFile, err)
+	}
+	site.BaseURL = strings.TrimSuffix(site.BaseURL, "/")
+	return site, nil
+}
+
+// Initial is the first letter of the brand name, for the logo fallback.
+func (s Site) Initial() string {
+	for _, r := range s.Name {
+		return strings.ToUpper(string(r))
+	}
+	return ""
+}
+
+// AbsURL turns a site-relative URL like "/about/" into an absolute one.
+func (s Site) AbsURL(rel string) string {
+	return s.BaseURL + "/" + strings.TrimPrefix(rel, "/")
+}
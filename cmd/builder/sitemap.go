@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// lastmodFile records a content hash and date per page so <lastmod> only
+// moves when a page's content actually changes. It is tracked in git.
+const lastmodFile = "data/lastmod.json"
+
+// SitemapOptions controls how a page appears in sitemap.xml.
+type SitemapOptions struct {
+	Exclude    bool    `yaml:"exclude"`    // leave the page out and mark it noindex, e.g. thank-you pages
+	Priority   float64 `yaml:"priority"`   // 0.0 to 1.0; zero means unset
+	ChangeFreq string  `yaml:"changefreq"` // always, hourly, daily, weekly, monthly, yearly, never
+}
+
+var changeFreqs = map[string]bool{
+	"always": true, "hourly": true, "daily": true, "weekly": true,
+	"monthly": true, "yearly": true, "never": true,
+}
+
+// validateSitemap records invalid sitemap options in errs.
+func validateSitemap(pages []Page, errs *BuildError) {
+	for _, page := range pages {
+		opts := page.Sitemap
+		if opts.Priority < 0 || opts.Priority > 1 {
+			errs.Add(page.Path, "", fmt.Errorf("sitemap priority %v is outside 0.0-1.0", opts.Priority))
+		}
+		if opts.ChangeFreq != "" && !changeFreqs[opts.ChangeFreq] {
+			errs.Add(page.Path, "", fmt.Errorf("unknown sitemap changefreq %q", opts.ChangeFreq))
+		}
+	}
+}
+
+type lastmodEntry struct {
+	Hash    string `json:"hash"`
+	Lastmod string `json:"lastmod"` // YYYY-MM-DD
+}
+
+// updateLastmod compares each page's content hash with the recorded one and
+// returns the new state, keyed by page Path. Removed pages are dropped.
+func updateLastmod(pages []Page, now time.Time) (map[string]lastmodEntry, error) {
+	previous := make(map[string]lastmodEntry)
+	if raw, err := os.ReadFile(lastmodFile); err == nil {
+		if err := json.Unmarshal(raw, &previous); err != nil {
+			return nil, fmt.Errorf("%s: %w", lastmodFile, err)
+		}
+	}
+
+	state := make(map[string]lastmodEntry, len(pages))
+	for _, page := range pages {
+		content, err := json.Marshal(page)
+		if err != nil {
+			return nil, fmt.Errorf("%s: hashing content: %w", page.Path, err)
+		}
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+
+		if prev, ok := previous[page.Path]; ok && prev.Hash == hash {
+			state[page.Path] = prev
+			continue
+		}
+		state[page.Path] = lastmodEntry{Hash: hash, Lastmod: now.Format("2006-01-02")}
+	}
+	return state, nil
+}
+
+func saveLastmod(state map[string]lastmodEntry) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(lastmodFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(lastmodFile, append(raw, '\n'), 0644)
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	Lastmod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// writeSitemap writes sitemap.xml and robots.txt into dir.
+func writeSitemap(dir string, site Site, pages []Page, lastmod map[string]lastmodEntry) error {
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, page := range pages {
+		if page.Sitemap.Exclude {
+			continue
+		}
+		u := sitemapURL{
+			Loc:        site.AbsURL(page.URL()),
+			Lastmod:    lastmod[page.Path].Lastmod,
+			ChangeFreq: page.Sitemap.ChangeFreq,
+		}
+		if page.Sitemap.Priority > 0 {
+			u.Priority = fmt.Sprintf("%.1f", page.Sitemap.Priority)
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	sort.Slice(set.URLs, func(i, j int) bool { return set.URLs[i].Loc < set.URLs[j].Loc })
+
+	raw, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	sitemap := append([]byte(xml.Header), raw...)
+	if err := os.WriteFile(filepath.Join(dir, "sitemap.xml"), append(sitemap, '\n'), 0644); err != nil {
+		return err
+	}
+
+	robots := strings.Join([]string{
+		"User-agent: *",
+		"Allow: /",
+		"",
+		"Sitemap: " + site.AbsURL("sitemap.xml"),
+		"",
+	}, "\n")
+	return os.WriteFile(filepath.Join(dir, "robots.txt"), []byte(robots), 0644)
+}
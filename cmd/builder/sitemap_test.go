@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateLastmod(t *testing.T) {
+	t.Chdir(t.TempDir())
+	day1 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 7)
+
+	kept := Page{Title: "About", Path: "about/index.html"}
+	edited := Page{Title: "VAT", Path: "vat/index.html"}
+	removed := Page{Title: "Old", Path: "old/index.html"}
+	state, err := updateLastmod([]Page{kept, edited, removed}, day1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveLastmod(state); err != nil {
+		t.Fatal(err)
+	}
+
+	edited.Description = "Now with a description"
+	added := Page{Title: "New", Path: "new/index.html"}
+	state, err = updateLastmod([]Page{kept, edited, added}, day2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		page Page
+		want string // lastmod, "" when the page has no entry
+	}{
+		{kept, "2026-03-01"},
+		{edited, "2026-03-08"},
+		{added, "2026-03-08"},
+		{removed, ""},
+	}
+	for _, tt := range tests {
+		if got := state[tt.page.Path].Lastmod; got != tt.want {
+			t.Errorf("%s: lastmod %q, want %q", tt.page.Path, got, tt.want)
+		}
+	}
+	if state[kept.Path].Hash == state[edited.Path].Hash {
+		t.Error("different pages hash the same")
+	}
+}
+
+func TestUpdateLastmodRejectsCorruptState(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(lastmodFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lastmodFile, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := updateLastmod([]Page{{Path: "index.html"}}, time.Now()); err == nil {
+		t.Error("corrupt lastmod file accepted")
+	}
+}
+
+func TestWriteSitemap(t *testing.T) {
+	dir := t.TempDir()
+	site := Site{BaseURL: "https://www.example.co.za"}
+	pages := []Page{
+		{Path: "vat/index.html", Sitemap: SitemapOptions{Priority: 0.8, ChangeFreq: "monthly"}},
+		{Path: "index.html"},
+		{Path: "vat/thank-you/index.html", Sitemap: SitemapOptions{Exclude: true}},
+	}
+	lastmod := map[string]lastmodEntry{"index.html": {Lastmod: "2026-03-01"}}
+	if err := writeSitemap(dir, site, pages, lastmod); err != nil {
+		t.Fatal(err)
+	}
+
+	sitemap := readFile(t, dir, "sitemap.xml")
+	for _, want := range []string{
+		"<loc>https://www.example.co.za/</loc>\n    <lastmod>2026-03-01</lastmod>",
+		"<loc>https://www.example.co.za/vat/</loc>\n    <changefreq>monthly</changefreq>\n    <priority>0.8</priority>",
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("sitemap.xml lacks %q:\n%s", want, sitemap)
+		}
+	}
+	if strings.Contains(sitemap, "thank-you") {
+		t.Error("sitemap.xml lists an excluded page")
+	}
+	if strings.Index(sitemap, "example.co.za/</loc>") > strings.Index(sitemap, "/vat/</loc>") {
+		t.Error("sitemap.xml is not sorted by URL")
+	}
+	if robots := readFile(t, dir, "robots.txt"); !strings.Contains(robots, "Sitemap: https://www.example.co.za/sitemap.xml\n") {
+		t.Errorf("robots.txt lacks the sitemap:\n%s", robots)
+	}
+}
+
+func TestValidateSitemap(t *testing.T) {
+	var errs BuildError
+	validateSitemap([]Page{
+		{Path: "ok/index.html", Sitemap: SitemapOptions{Priority: 1, ChangeFreq: "weekly"}},
+		{Path: "high/index.html", Sitemap: SitemapOptions{Priority: 1.5}},
+		{Path: "freq/index.html", Sitemap: SitemapOptions{ChangeFreq: "fortnightly"}},
+	}, &errs)
+	if len(errs.Problems) != 2 || errs.Problems[0].Page != "high/index.html" || errs.Problems[1].Page != "freq/index.html" {
+		t.Errorf("problems = %v, want one each for high/ and freq/", errs.Problems)
+	}
+}
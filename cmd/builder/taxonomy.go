@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/directory"
+)
+
+// taxonomyFile configures the taxonomy pages. Without it none are generated.
+const taxonomyFile = "data/taxonomy.yaml"
+
+// Taxonomies group practitioners and pages by term, e.g. the "service"
+// taxonomy has a term "VAT Registration". Practitioners get their terms from
+// their records (directory.Practitioner.Terms); pages declare theirs with
+// Page.Terms. The builder generates an index page per taxonomy, a page per
+// term, and a page per combination of terms, e.g.
+// /services/vat-registration/cape-town/.
+type Taxonomies struct {
+	Taxonomies   []Taxonomy    `yaml:"taxonomies"`
+	Combinations []Combination `yaml:"combinations"`
+}
+
+// Taxonomy is one way of grouping, with the copy for its pages. Title and
+// Description may mention the term as {<name>}, e.g. "{service} Practitioners".
+type Taxonomy struct {
+	Name             string `yaml:"name"`  // key in terms, e.g. "service"
+	Path             string `yaml:"path"`  // URL directory, e.g. "services"
+	Label            string `yaml:"label"` // index page heading, e.g. "Services"
+	Title            string `yaml:"title"`
+	Description      string `yaml:"description"`
+	MinPractitioners int    `yaml:"min_practitioners"` // terms with fewer get no page
+}
+
+// Combination is a page per set of terms from several taxonomies, listing the
+// practitioners who have them all. Pages live below the first taxonomy's term
+// page, which lists them under Label. Title and Description may mention every
+// term, e.g. "{service} in {city}".
+type Combination struct {
+	Terms            []string `yaml:"terms"` // taxonomy names, e.g. [service, city]
+	Label            string   `yaml:"label"` // e.g. "By City"
+	Title            string   `yaml:"title"`
+	Description      string   `yaml:"description"`
+	MinPractitioners int      `yaml:"min_practitioners"`
+}
+
+// loadTaxonomies reads taxonomyFile. A missing file means no taxonomies.
+func loadTaxonomies() (Taxonomies, error) {
+	var tax Taxonomies
+	raw, err := os.ReadFile(taxonomyFile)
+	if os.IsNotExist(err) {
+		return tax, nil
+	}
+	if err != nil {
+		return tax, err
+	}
+	// Unknown keys fail the build rather than silently dropping a setting
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&tax); err != nil && err != io.EOF {
+		return tax, fmt.Errorf("%s: %w", taxonomyFile, err)
+	}
+	if err := tax.check(); err != nil {
+		return tax, fmt.Errorf("%s: %w", taxonomyFile, err)
+	}
+	return tax, nil
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+func (tax Taxonomies) check() error {
+	names, paths := make(map[string]bool), make(map[string]bool)
+	for _, t := range tax.Taxonomies {
+		if t.Name == "" || t.Path == "" || t.Label == "" || t.Title == "" {
+			return fmt.Errorf("taxonomy %q: name, path, label and title are required", t.Name)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("taxonomy %q is defined twice", t.Name)
+		}
+		if paths[t.Path] {
+			return fmt.Errorf("taxonomy %q: path %q is used twice", t.Name, t.Path)
+		}
+		names[t.Name], paths[t.Path] = true, true
+		if err := checkPlaceholders(t.Title+t.Description, []string{t.Name}); err != nil {
+			return fmt.Errorf("taxonomy %q: %w", t.Name, err)
+		}
+	}
+	for _, c := range tax.Combinations {
+		name := strings.Join(c.Terms, "+")
+		if len(c.Terms) < 2 || c.Label == "" || c.Title == "" {
+			return fmt.Errorf("combination %q: at least two terms, a label and a title are required", name)
+		}
+		for _, term := range c.Terms {
+			if tax.taxonomy(term) == nil {
+				return fmt.Errorf("combination %q: unknown taxonomy %q", name, term)
+			}
+		}
+		if err := checkPlaceholders(c.Title+c.Description, c.Terms); err != nil {
+			return fmt.Errorf("combination %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkPlaceholders reports a {name} in text that isn't one of names.
+func checkPlaceholders(text string, names []string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(names, m[1]) {
+			return fmt.Errorf("unknown placeholder %s", m[0])
+		}
+	}
+	return nil
+}
+
+func (tax Taxonomies) taxonomy(name string) *Taxonomy {
+	for i := range tax.Taxonomies {
+		if tax.Taxonomies[i].Name == name {
+			return &tax.Taxonomies[i]
+		}
+	}
+	return nil
+}
+
+// validateTerms records every page term in a taxonomy that isn't configured.
+func validateTerms(pages []Page, tax Taxonomies, errs *BuildError) {
+	for _, page := range pages {
+		for name := range page.Terms {
+			if tax.taxonomy(name) == nil {
+				errs.Add(page.Path, "", fmt.Errorf("terms: unknown taxonomy %q (see %s)", name, taxonomyFile))
+			}
+		}
+	}
+}
+
+// termGroup is everything tagged with one term, or one combination of terms.
+type termGroup struct {
+	Slugs  []string          // one per taxonomy, e.g. ["vat-registration", "cape-town"]
+	Labels map[string]string // taxonomy name -> term as first written
+	Cards  []PractitionerCard
+}
+
+func (g termGroup) fill(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return g.Labels[m[1:len(m)-1]]
+	})
+}
+
+// groupTerms groups the practitioners by their terms in names, one group per
+// combination of terms, sorted by slug.
+func groupTerms(cards []PractitionerCard, names []string) []*termGroup {
+	groups := make(map[string]*termGroup)
+	for _, card := range cards {
+		terms := card.Terms()
+		combos := []*termGroup{{Labels: map[string]string{}}}
+		for _, name := range names {
+			var next []*termGroup
+			for _, combo := range combos {
+				for _, term := range terms[name] {
+					if slugify(term) == "" {
+						continue
+					}
+					g := &termGroup{Slugs: append(slices.Clone(combo.Slugs), slugify(term)), Labels: map[string]string{name: term}}
+					for k, v := range combo.Labels {
+						g.Labels[k] = v
+					}
+					next = append(next, g)
+				}
+			}
+			combos = next
+		}
+		seen := make(map[string]bool) // a card counts once, however its terms are spelled
+		for _, combo := range combos {
+			key := strings.Join(combo.Slugs, "/")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if groups[key] == nil {
+				groups[key] = combo
+			}
+			groups[key].Cards = append(groups[key].Cards, card)
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]*termGroup, len(keys))
+	for i, key := range keys {
+		out[i] = groups[key]
+	}
+	return out
+}
+
+// slugify turns a term into a URL segment: "Company Registration (CIPC)"
+// becomes "company-registration-cipc".
+func slugify(term string) string {
+	return directory.Slug(term)
+}
+
+// taxonomyPages generates the taxonomy index, term and combination pages.
+// Terms and combinations with fewer practitioners than their threshold get no
+// page, and generated pages never replace a page that already exists. Two
+// generated pages with the same URL, e.g. from a city and a province both
+// spelled "Gauteng", are recorded in errs.
+func taxonomyPages(pages []Page, site Site, tax Taxonomies, errs *BuildError) []Page {
+	exists := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		exists[page.URL()] = true
+	}
+	cards := practitionerCards(pages)
+
+	var out []Page
+	generated := make(map[string]string) // URL -> what generated it
+	add := func(page Page, origin string) bool {
+		if prev, ok := generated[page.URL()]; ok {
+			errs.Add(page.Path, "", fmt.Errorf("%s: both %s and %s generate this page; two of their terms have the same slug", taxonomyFile, prev, origin))
+			return false
+		}
+		if exists[page.URL()] {
+			return false
+		}
+		exists[page.URL()] = true
+		generated[page.URL()] = origin
+		out = append(out, page)
+		return true
+	}
+
+	for _, t := range tax.Taxonomies {
+		origin := fmt.Sprintf("taxonomy %q", t.Name)
+		var terms []Page
+		for _, g := range groupTerms(cards, []string{t.Name}) {
+			if len(g.Cards) < max(t.MinPractitioners, 1) {
+				continue
+			}
+			url := "/" + t.Path + "/" + g.Slugs[0] + "/"
+			page := taxonomyPage(url, g.fill(t.Title), g.fill(t.Description), site, g.Cards, 0.6)
+			page.Sections = append(page.Sections, relatedPages(pages, t.Name, g.Labels[t.Name])...)
+			for _, c := range tax.Combinations {
+				if c.Terms[0] == t.Name {
+					page.Sections = append(page.Sections, combinationPages(url, g, c, site, add)...)
+				}
+			}
+			if add(page, origin) {
+				terms = append(terms, page)
+			}
+		}
+		if len(terms) > 0 {
+			add(Page{
+				Title:       t.Label + " | " + site.Name,
+				Description: fmt.Sprintf("%s covered by the registered tax practitioners listed on %s.", t.Label, site.Name),
+				Path:        t.Path + "/index.html",
+				Nav:         NavOptions{Hide: true},
+				Sitemap:     SitemapOptions{Priority: 0.5, ChangeFreq: "weekly"},
+				Sections: []Section{
+					{TemplateName: "hero", Data: HeroData{Title: t.Label, Subtitle: "Choose one to see the registered practitioners listed for it.", BackgroundImage: directoryImage}},
+					{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+					{TemplateName: "child_pages", Data: ChildPagesData{}},
+				},
+				source: taxonomyFile,
+			}, origin)
+		}
+	}
+	return out
+}
+
+// combinationPages generates the pages for combination c below the term page
+// at parent, and returns a section listing them.
+func combinationPages(parent string, term *termGroup, c Combination, site Site, add func(Page, string) bool) []Section {
+	origin := fmt.Sprintf("combination %q", strings.Join(c.Terms, "+"))
+	var list []Card
+	for _, g := range groupTerms(term.Cards, c.Terms) {
+		if g.Slugs[0] != term.Slugs[0] || len(g.Cards) < max(c.MinPractitioners, 1) {
+			continue
+		}
+		url := parent + strings.Join(g.Slugs[1:], "/") + "/"
+		page := taxonomyPage(url, g.fill(c.Title), g.fill(c.Description), site, g.Cards, 0.5)
+		if add(page, origin) {
+			list = append(list, Card{Title: page.plainTitle(), Description: page.Description, URL: page.URL()})
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return []Section{{TemplateName: "child_pages", Data: ChildPagesData{Title: c.Label, Items: list}}}
+}
+
+func taxonomyPage(url, title, description string, site Site, cards []PractitionerCard, priority float64) Page {
+	return Page{
+		Title:       title + " | " + site.Name,
+		Description: description,
+		Path:        strings.TrimPrefix(url, "/") + "index.html",
+		Nav:         NavOptions{Hide: true},
+		Sitemap:     SitemapOptions{Priority: priority, ChangeFreq: "weekly"},
+		Sections: []Section{
+			{TemplateName: "hero", Data: HeroData{Title: title, Subtitle: description, BackgroundImage: directoryImage}},
+			{TemplateName: "breadcrumbs", Data: BreadcrumbsData{}},
+			{TemplateName: "practitioner_list", Data: PractitionerListData{Title: "Registered Practitioners", Items: cards}},
+		},
+		source: taxonomyFile,
+	}
+}
+
+// relatedPages returns a section linking to the pages tagged with term, if
+// there are any.
+func relatedPages(pages []Page, taxonomy, term string) []Section {
+	var list []Card
+	for _, page := range pages {
+		tagged := slices.ContainsFunc(page.Terms[taxonomy], func(t string) bool { return slugify(t) == slugify(term) })
+		if tagged && !page.Sitemap.Exclude {
+			list = append(list, Card{Title: page.plainTitle(), Description: page.Description, URL: page.URL()})
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return []Section{{TemplateName: "child_pages", Data: ChildPagesData{Title: "How We Can Help", Items: list}}}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"website/internal/directory"
+)
+
+// testPractitioner is a valid record offering services in areas.
+func testPractitioner(slug string, services, areas, provinces []string) directory.Practitioner {
+	return directory.Practitioner{
+		Slug:         slug,
+		Name:         dirLabel(slug),
+		Practice:     dirLabel(slug) + " Tax",
+		Registration: directory.Registration{Body: "SAIT", Number: "12345"},
+		Services:     services,
+		Areas:        areas,
+		Provinces:    provinces,
+	}
+}
+
+func TestGroupTerms(t *testing.T) {
+	var cards []PractitionerCard
+	for _, p := range []directory.Practitioner{
+		testPractitioner("thandi", []string{"VAT Registration", "Payroll"}, []string{"Cape Town"}, nil),
+		testPractitioner("sipho", []string{"VAT registration"}, []string{"Cape Town", "Durban"}, nil),
+		testPractitioner("lerato", []string{"Payroll", "payroll", "!!"}, []string{"Durban"}, nil),
+	} {
+		cards = append(cards, PractitionerCard{Practitioner: p})
+	}
+	tests := []struct {
+		names []string
+		want  string // "slugs: slugs of the cards" per group
+	}{
+		{[]string{"service"}, "payroll: thandi lerato; vat-registration: thandi sipho"},
+		{[]string{"city"}, "cape-town: thandi sipho; durban: sipho lerato"},
+		{[]string{"service", "city"}, "payroll/cape-town: thandi; payroll/durban: lerato; vat-registration/cape-town: thandi sipho; vat-registration/durban: sipho"},
+		{[]string{"province"}, ""},
+	}
+	for _, tt := range tests {
+		var groups []string
+		for _, g := range groupTerms(cards, tt.names) {
+			var slugs []string
+			for _, c := range g.Cards {
+				slugs = append(slugs, c.Slug)
+			}
+			groups = append(groups, strings.Join(g.Slugs, "/")+": "+strings.Join(slugs, " "))
+		}
+		if got := strings.Join(groups, "; "); got != tt.want {
+			t.Errorf("groupTerms(%q):\n got %s\nwant %s", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestTermGroupFill(t *testing.T) {
+	g := termGroup{Labels: map[string]string{"service": "VAT Registration", "city": "Cape Town"}}
+	if got, want := g.fill("{service} in {city}"), "VAT Registration in Cape Town"; got != want {
+		t.Errorf("fill = %q, want %q", got, want)
+	}
+}
+
+// testTaxonomies has service and city pages, and service-in-city pages below
+// the service pages.
+func testTaxonomies(minService, minCity, minCombination int) Taxonomies {
+	return Taxonomies{
+		Taxonomies: []Taxonomy{
+			{Name: "service", Path: "services", Label: "Services", Title: "{service} Practitioners", MinPractitioners: minService},
+			{Name: "city", Path: "cities", Label: "Cities", Title: "Tax Practitioners in {city}", MinPractitioners: minCity},
+		},
+		Combinations: []Combination{
+			{Terms: []string{"service", "city"}, Label: "By City", Title: "{service} in {city}", MinPractitioners: minCombination},
+		},
+	}
+}
+
+func TestTaxonomyPages(t *testing.T) {
+	site := Site{Name: "SA Tax Returns"}
+	pages := []Page{
+		profilePage(testPractitioner("thandi", []string{"VAT Registration", "Payroll"}, []string{"Cape Town"}, nil), nil),
+		profilePage(testPractitioner("sipho", []string{"VAT Registration"}, []string{"Cape Town", "Durban"}, nil), nil),
+	}
+	tests := []struct {
+		name  string
+		tax   Taxonomies
+		extra []Page // pages written by hand
+		want  []string
+	}{
+		{
+			name: "every term",
+			tax:  testTaxonomies(0, 0, 0),
+			want: []string{
+				"/services/payroll/cape-town/", "/services/payroll/",
+				"/services/vat-registration/cape-town/", "/services/vat-registration/durban/", "/services/vat-registration/",
+				"/services/",
+				"/cities/cape-town/", "/cities/durban/", "/cities/",
+			},
+		},
+		{
+			name: "thresholds",
+			tax:  testTaxonomies(2, 1, 2),
+			want: []string{
+				"/services/vat-registration/cape-town/", "/services/vat-registration/", "/services/",
+				"/cities/cape-town/", "/cities/durban/", "/cities/",
+			},
+		},
+		{
+			name: "no term reaches the threshold",
+			tax:  testTaxonomies(3, 3, 3),
+			want: nil,
+		},
+		{
+			name:  "pages written by hand are kept",
+			tax:   testTaxonomies(2, 2, 2),
+			extra: []Page{{Path: "services/vat-registration/index.html", Title: "VAT Registration"}},
+			want:  []string{"/services/vat-registration/cape-town/", "/cities/cape-town/", "/cities/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs BuildError
+			var got []string
+			for _, page := range taxonomyPages(append(slices.Clone(pages), tt.extra...), site, tt.tax, &errs) {
+				got = append(got, page.URL())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("generated:\n got %q\nwant %q", got, tt.want)
+			}
+			if len(errs.Problems) > 0 {
+				t.Errorf("problems: %v", errs.Problems)
+			}
+		})
+	}
+}
+
+func TestTaxonomyPageCopy(t *testing.T) {
+	pages := []Page{
+		profilePage(testPractitioner("thandi", []string{"VAT Registration"}, []string{"Cape Town"}, nil), nil),
+		{Path: "registrations/vat/index.html", Title: "VAT Registration | SA Tax Returns", Terms: map[string][]string{"service": {"vat registration"}}},
+	}
+	var errs BuildError
+	generated := taxonomyPages(pages, Site{Name: "SA Tax Returns"}, testTaxonomies(0, 0, 0), &errs)
+	byURL := make(map[string]Page)
+	for _, page := range generated {
+		byURL[page.URL()] = page
+	}
+
+	term := byURL["/services/vat-registration/"]
+	if term.Title != "VAT Registration Practitioners | SA Tax Returns" {
+		t.Errorf("term page title %q", term.Title)
+	}
+	var sections []string
+	for _, s := range term.Sections {
+		if data, ok := s.Data.(ChildPagesData); ok {
+			var urls []string
+			for _, item := range data.Items {
+				urls = append(urls, item.URL)
+			}
+			sections = append(sections, data.Title+": "+strings.Join(urls, " "))
+			continue
+		}
+		sections = append(sections, s.TemplateName)
+	}
+	want := "hero, breadcrumbs, practitioner_list, How We Can Help: /registrations/vat/, By City: /services/vat-registration/cape-town/"
+	if got := strings.Join(sections, ", "); got != want {
+		t.Errorf("term page sections:\n got %s\nwant %s", got, want)
+	}
+	if combo := byURL["/services/vat-registration/cape-town/"]; combo.Title != "VAT Registration in Cape Town | SA Tax Returns" {
+		t.Errorf("combination page title %q", combo.Title)
+	}
+}
+
+func TestTaxonomyPagesReportCollisions(t *testing.T) {
+	pages := []Page{
+		profilePage(testPractitioner("thandi", []string{"VAT Registration"}, []string{"Gauteng"}, []string{"Gauteng"}), nil),
+	}
+	tax := testTaxonomies(0, 0, 0)
+	tax.Taxonomies = append(tax.Taxonomies, Taxonomy{Name: "province", Path: "provinces", Label: "Provinces", Title: "Tax Practitioners in {province}"})
+	tax.Combinations = append(tax.Combinations, Combination{Terms: []string{"service", "province"}, Label: "By Province", Title: "{service} in {province}"})
+
+	var errs BuildError
+	taxonomyPages(pages, Site{Name: "SA Tax Returns"}, tax, &errs)
+	if len(errs.Problems) != 1 {
+		t.Fatalf("problems = %v, want one collision", errs.Problems)
+	}
+	p := errs.Problems[0]
+	if p.Page != "services/vat-registration/gauteng/index.html" || !strings.Contains(p.Err.Error(), `both combination "service+city" and combination "service+province"`) {
+		t.Errorf("problem = %s: %v", p.Page, p.Err)
+	}
+}
+
+func TestTaxonomiesCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*Taxonomies)
+		want string // substring of the error, "" for none
+	}{
+		{"valid", func(*Taxonomies) {}, ""},
+		{"missing title", func(tax *Taxonomies) { tax.Taxonomies[0].Title = "" }, "are required"},
+		{"defined twice", func(tax *Taxonomies) { tax.Taxonomies[1].Name = "service" }, `taxonomy "service" is defined twice`},
+		{"path used twice", func(tax *Taxonomies) { tax.Taxonomies[1].Path = "services" }, `path "services" is used twice`},
+		{"unknown placeholder", func(tax *Taxonomies) { tax.Taxonomies[1].Title = "Practitioners in {town}" }, "unknown placeholder {town}"},
+		{"other taxonomy's placeholder", func(tax *Taxonomies) { tax.Taxonomies[0].Title = "{service} in {city}" }, "unknown placeholder {city}"},
+		{"single-term combination", func(tax *Taxonomies) { tax.Combinations[0].Terms = []string{"service"} }, "at least two terms"},
+		{"combination of unknown taxonomy", func(tax *Taxonomies) { tax.Combinations[0].Terms[1] = "town" }, `unknown taxonomy "town"`},
+		{"combination placeholder", func(tax *Taxonomies) { tax.Combinations[0].Description = "{province}" }, "unknown placeholder {province}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tax := testTaxonomies(0, 0, 0)
+			tt.edit(&tax)
+			err := tax.check()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTerms(t *testing.T) {
+	var errs BuildError
+	validateTerms([]Page{
+		{Path: "registrations/vat/index.html", Terms: map[string][]string{"service": {"VAT Registration"}}},
+		{Path: "about/index.html", Terms: map[string][]string{"services": {"VAT Registration"}}},
+	}, testTaxonomies(0, 0, 0), &errs)
+	if len(errs.Problems) != 1 || errs.Problems[0].Page != "about/index.html" {
+		t.Errorf("problems = %v, want the unknown taxonomy on about/", errs.Problems)
+	}
+}
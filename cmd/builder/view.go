@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PageView is what layouts, header and footer templates receive as ".".
+// Page is embedded so {{ .Title }} and friends keep working.
+type PageView struct {
+	Page
+	Site Site
+	Nav  []NavItem // site navigation with this page marked active
+
+	Breadcrumbs []Crumb // from the home page down to this one
+}
+
+// CanonicalURL is the absolute trailing-slash address of the page, used for
+// <link rel="canonical"> so /foo/ and /foo/index.html count as one URL.
+func (v PageView) CanonicalURL() string {
+	return v.Site.AbsURL(v.URL())
+}
+
+// PageID is how templates refer to a page in {{ url "<id>" }}. It defaults
+// to the page's directory, e.g. "submissions/vat", and "home" for the root.
+func (p Page) PageID() string {
+	if p.ID != "" {
+		return p.ID
+	}
+	if id := strings.Trim(p.URL(), "/"); id != "" {
+		return id
+	}
+	return "home"
+}
+
+// indexPages maps every page ID to its URL, recording duplicate IDs in errs.
+func indexPages(pages []Page, errs *BuildError) map[string]string {
+	urls := make(map[string]string, len(pages))
+	for _, page := range pages {
+		id := page.PageID()
+		if _, ok := urls[id]; ok {
+			errs.Add(page.Path, "", fmt.Errorf("duplicate page id %q", id))
+			continue
+		}
+		urls[id] = page.URL()
+	}
+	return urls
+}
+
+// SocialTitle is the title shown in link previews.
+func (v PageView) SocialTitle() string {
+	if v.Social.Title != "" {
+		return v.Social.Title
+	}
+	return v.Title
+}
+
+// SocialType is the OpenGraph object type.
+func (v PageView) SocialType() string {
+	if v.Social.Type != "" {
+		return v.Social.Type
+	}
+	return "website"
+}
+
+// SocialImage is the absolute URL of the preview image: the page's own
+// social image, else the first hero background. Empty if there is neither.
+func (v PageView) SocialImage() string {
+	img := v.Social.Image
+	if img == "" {
+		img = v.heroImage()
+	}
+	if img == "" || strings.HasPrefix(img, "http://") || strings.HasPrefix(img, "https://") {
+		return img
+	}
+	return v.Site.AbsURL(img)
+}
+
+func (p Page) heroImage() string {
+	for _, s := range p.Sections {
+		if hero, ok := s.Data.(HeroData); ok && hero.BackgroundImage != "" {
+			return hero.BackgroundImage
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestPageURLAndID(t *testing.T) {
+	tests := []struct {
+		page    Page
+		url, id string
+	}{
+		{Page{Path: "index.html"}, "/", "home"},
+		{Page{Path: "about/index.html"}, "/about/", "about"},
+		{Page{Path: "submissions/vat/index.html"}, "/submissions/vat/", "submissions/vat"},
+		{Page{Path: "privacy/index.html", ID: "privacy-notice"}, "/privacy/", "privacy-notice"},
+		{Page{Path: "404.html"}, "/404.html", "404.html"},
+	}
+	for _, tt := range tests {
+		if got := tt.page.URL(); got != tt.url {
+			t.Errorf("%s: URL() = %q, want %q", tt.page.Path, got, tt.url)
+		}
+		if got := tt.page.PageID(); got != tt.id {
+			t.Errorf("%s: PageID() = %q, want %q", tt.page.Path, got, tt.id)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	v := PageView{Page: Page{Path: "submissions/vat/index.html"}, Site: Site{BaseURL: "https://www.example.co.za"}}
+	if got, want := v.CanonicalURL(), "https://www.example.co.za/submissions/vat/"; got != want {
+		t.Errorf("CanonicalURL() = %q, want %q", got, want)
+	}
+}
+
+func TestIndexPages(t *testing.T) {
+	var errs BuildError
+	urls := indexPages([]Page{
+		{Path: "index.html"},
+		{Path: "contact/index.html"},
+		{Path: "help/index.html", ID: "contact"}, // clashes with the contact page's default ID
+	}, &errs)
+	if urls["home"] != "/" || urls["contact"] != "/contact/" {
+		t.Errorf("urls = %v", urls)
+	}
+	if len(errs.Problems) != 1 || errs.Problems[0].Page != "help/index.html" {
+		t.Errorf("problems = %v, want the duplicate id on help/", errs.Problems)
+	}
+}
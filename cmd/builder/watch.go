@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"website/internal/directory"
+	"website/internal/reviews"
+)
+
+// Change is a set of flags describing which kinds of input changed.
+type Change int
+
+const (
+	changeGo     Change = 1 << iota // builder sources: needs a recompile and restart
+	changePages                     // templates or content files: rebuild CSS and HTML
+	changeCSS                       // stylesheet inputs only: rebuild CSS
+	changeAssets                    // static assets: copy to build/assets
+)
+
+// watchedInputs maps each input the dev server watches to the work a change
+// to it requires. Directories are watched recursively.
+var watchedInputs = []struct {
+	Path   string
+	Change Change
+}{
+	{"cmd/builder", changeGo},
+	{"internal", changeGo},
+	{"go.mod", changeGo},
+	{"go.sum", changeGo},
+	{"components", changePages},
+	{contentDir, changePages},
+	{siteFile, changePages},
+	{directory.Dir, changePages},
+	{reviews.Dir, changePages},
+	{taxonomyFile, changePages},
+	{"styles", changeCSS},
+	{"tailwind.config.js", changeCSS},
+	{"assets", changeAssets},
+}
+
+// changedSince reports which kinds of input were modified after t.
+func changedSince(t time.Time) Change {
+	var changed Change
+	for _, in := range watchedInputs {
+		if changed&in.Change != 0 {
+			continue
+		}
+		if modifiedSince(in.Path, t) {
+			changed |= in.Change
+		}
+	}
+	return changed
+}
+
+// modifiedSince reports whether path, or any file below it, has a
+// modification time after t. Missing paths are treated as unchanged.
+func modifiedSince(path string, t time.Time) bool {
+	found := false
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == filepath.FromSlash(cssFile) {
+			return nil // rebuildCSS writes the stylesheet itself
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(t) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// devBinary is where the dev server compiles its replacement on Go changes.
+func devBinary() string {
+	name := "sa-tax-builder-dev"
+	if os.PathSeparator == '\\' {
+		name += ".exe"
+	}
+	return filepath.Join(os.TempDir(), name)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// fakeSMTP is a minimal in-process SMTP server for local development. It
+// accepts every message and saves it as a .eml file instead of delivering
+// it, so the whole mail path can be exercised without a real mail server.
+type fakeSMTP struct {
+	ln    net.Listener
+	dir   string
+	count atomic.Int64
+}
+
+// startFakeSMTP listens on a free local port and saves messages to dir.
+func startFakeSMTP(dir string) (*fakeSMTP, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f := &fakeSMTP{ln: ln, dir: dir}
+	go f.serve()
+	return f, nil
+}
+
+// Addr is the host:port to send mail to.
+func (f *fakeSMTP) Addr() string {
+	return f.ln.Addr().String()
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+// handle speaks just enough SMTP for net/smtp.SendMail.
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		return tp.PrintfLine(format, args...) == nil
+	}
+
+	var from string
+	var to []string
+	if !reply("220 localhost fake SMTP ready") {
+		return
+	}
+	for {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			from, to = smtpPath(arg), nil
+			reply("250 OK")
+		case "RCPT":
+			to = append(to, smtpPath(arg))
+			reply("250 OK")
+		case "DATA":
+			if from == "" || len(to) == 0 {
+				reply("503 need MAIL and RCPT first")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			raw, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			if err := f.save(from, to, raw); err != nil {
+				log.Printf("fake smtp: %v", err)
+				reply("451 could not save message")
+				continue
+			}
+			from, to = "", nil
+			reply("250 OK")
+		case "RSET":
+			from, to = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func (f *fakeSMTP) save(from string, to []string, raw []byte) error {
+	n := f.count.Add(1)
+	name := filepath.Join(f.dir, fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405"), n))
+	if err := os.WriteFile(name, raw, 0600); err != nil {
+		return err
+	}
+	subject := ""
+	if msg, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(raw))).ReadMIMEHeader(); err == nil {
+		subject, _ = new(mime.WordDecoder).DecodeHeader(msg.Get("Subject"))
+	}
+	log.Printf("fake smtp: %s -> %s %q saved to %s", from, strings.Join(to, ", "), subject, name)
+	return nil
+}
+
+// smtpPath extracts the address from "FROM:<a@b>" or "TO:<a@b>".
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path = strings.TrimSpace(path)
+	if i := strings.IndexByte(path, ' '); i >= 0 {
+		path = path[:i] // drop parameters like SIZE=
+	}
+	return strings.Trim(path, "<>")
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"website/internal/forms"
+)
+
+// maxBodyBytes caps a form post apart from its files; the largest field
+// limit is far below it. Uploads add the form's file size limits on top.
+const maxBodyBytes = 64 << 10
+
+// maxMemoryBytes is how much of a multipart post is held in memory; larger
+// files are buffered in temporary files until the post is handled.
+const maxMemoryBytes = 1 << 20
+
+// server handles form posts.
+type server struct {
+	forms       forms.Manifest
+	submissions *Store
+	rejected    *Store
+	listings    *Store // applications to the practitioner directory
+	reviews     *Store // reviews of practitioners waiting for moderation
+	spam        spamConfig
+	limiter     *rateLimiter
+	notifier    *Notifier // nil when email is off
+	dataDir     string    // uploads are saved under it
+	siteURL     string    // prefix for redirects when the site is on another origin
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /forms/{id}", s.handleSubmit)
+	mux.HandleFunc("GET /forms/{id}/token", s.handleToken)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+func (s *server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.forms[r.PathValue("id")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now().UTC()
+	ip := clientIP(r, s.spam.trustProxy)
+	if ok, first := s.limiter.Allow(ip, now); !ok {
+		if first {
+			s.reject(r, form, ip, rejectRateLimited, "further posts are turned away unlogged until the rate falls")
+		}
+		http.Error(w, "too many submissions, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var files map[string][]*multipart.FileHeader
+	if form.HasFiles() {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes+form.MaxUploadBytes())
+		err := r.ParseMultipartForm(maxMemoryBytes)
+		if err != nil && err != http.ErrNotMultipart {
+			http.Error(w, "could not read form, the files may be too large", http.StatusBadRequest)
+			return
+		}
+		if r.MultipartForm != nil {
+			defer r.MultipartForm.RemoveAll()
+			files = r.MultipartForm.File
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "could not read form", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Bots that fill the hidden field get the normal success response
+	if r.PostForm.Get(forms.HoneypotField) != "" {
+		s.reject(r, form, ip, rejectHoneypot, "")
+		http.Redirect(w, r, s.siteURL+form.Success, http.StatusSeeOther)
+		return
+	}
+	if s.spam.secret != nil {
+		token := r.PostForm.Get(forms.TokenField)
+		if err := forms.CheckToken(s.spam.secret, form.ID, token, now, s.spam.minFill, s.spam.tokenTTL); err != nil {
+			s.reject(r, form, ip, rejectToken, err.Error())
+			s.renderProblems(w, form, []string{"Sorry, we couldn't accept your message. Please make sure JavaScript is enabled, reload the page and send it again."})
+			return
+		}
+	}
+
+	values, problems := form.Validate(r.PostForm, files)
+	if len(problems) > 0 {
+		s.renderProblems(w, form, form.ProblemList(problems))
+		return
+	}
+
+	sub := Submission{ID: newID(), Form: form.ID, Values: values, Received: now}
+	if form.Consent != nil {
+		sub.Consent = &ConsentRecord{Version: form.Consent.Version, Given: now}
+	}
+	if len(files) > 0 {
+		saved, err := saveUploads(s.dataDir, sub.ID, form, files)
+		if err != nil {
+			log.Printf("saving uploads for %s: %v", form.ID, err)
+			http.Error(w, "could not save your files, please try again", http.StatusInternalServerError)
+			return
+		}
+		sub.Files = saved
+	}
+	var record any = sub
+	store := s.submissions
+	switch form.Kind {
+	case forms.KindListing:
+		record, store = Listing{Submission: sub, Moderation: Moderation{Status: statusPending}}, s.listings
+	case forms.KindReview:
+		record, store = ReviewRecord{Submission: sub, Moderation: Moderation{Status: statusPending}, Practitioner: form.Subject}, s.reviews
+	}
+	if err := store.Append(record); err != nil {
+		log.Printf("storing submission for %s: %v", form.ID, err)
+		http.Error(w, "could not save your message, please try again", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("stored submission %s for form %s", sub.ID, form.ID)
+	if s.notifier != nil {
+		// The submission is safe either way; mail problems are only logged
+		if err := s.notifier.Submitted(form, sub); err != nil {
+			log.Printf("queueing email for %s: %v", sub.ID, err)
+		}
+	}
+	http.Redirect(w, r, s.siteURL+form.Success, http.StatusSeeOther)
+}
+
+var problemsPage = template.Must(template.New("problems").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Please check your details</title>
+    <style>
+        body { margin: 0; padding: 48px 24px; font: 16px/1.5 ui-sans-serif, system-ui, sans-serif; color: #111827; background: #f9fafb; }
+        main { max-width: 560px; margin: 0 auto; background: #fff; padding: 40px; border-radius: 16px; border: 1px solid #f3f4f6; }
+        h1 { font-size: 24px; margin: 0 0 16px; }
+        li { color: #b91c1c; margin-bottom: 8px; }
+        a { color: #4f46e5; font-weight: 600; }
+    </style>
+</head>
+<body>
+    <main>
+        <h1>Please check your details</h1>
+        <ul>{{ range .Problems }}<li>{{ . }}</li>{{ end }}</ul>
+        <p><a href="{{ .Back }}" onclick="history.back(); return false;">Go back to the form</a></p>
+    </main>
+</body>
+</html>
+`))
+
+// handleToken issues a fresh spam token, so the minimum fill time counts
+// from when the visitor opened the page.
+func (s *server) handleToken(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.forms[r.PathValue("id")]
+	if !ok || s.spam.secret == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]string{"token": forms.SignToken(s.spam.secret, form.ID, time.Now())})
+}
+
+// reject logs a post turned away by the spam checks for later review.
+func (s *server) reject(r *http.Request, form forms.Form, ip, reason, detail string) {
+	rej := Rejection{
+		ID:       newID(),
+		Form:     form.ID,
+		Reason:   reason,
+		Detail:   detail,
+		IP:       ip,
+		Values:   r.PostForm,
+		Received: time.Now().UTC(),
+	}
+	if err := s.rejected.Append(rej); err != nil {
+		log.Printf("logging rejected post for %s: %v", form.ID, err)
+	}
+	log.Printf("rejected post for form %s from %s: %s", form.ID, ip, reason)
+}
+
+// renderProblems answers an invalid post with the list of problems. Going
+// back with the browser keeps what the visitor already typed.
+func (s *server) renderProblems(w http.ResponseWriter, form forms.Form, list []string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	problemsPage.Execute(w, struct {
+		Problems []string
+		Back     string
+	}{list, s.siteURL + form.Page})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"website/internal/directory"
+)
+
+// Listing is an application to be listed in the practitioner directory,
+// posted with the "Get Listed" form. It stays in the data directory, which
+// the builder never reads: only approving it writes a record to
+// data/practitioners/, so pending and rejected applications are never
+// published.
+type Listing struct {
+	Submission
+	Moderation
+	Slug string `json:"slug,omitempty"` // record written on approval
+}
+
+// runListings reviews directory applications and returns the exit code.
+//
+//	formserver listings [-all]
+//	formserver listings show <id>
+//	formserver listings approve [-slug name] <id>
+//	formserver listings reject [-reason text] <id>
+//
+// IDs may be shortened to any unique prefix. Flags go before the ID.
+func runListings(args []string) int {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("listings "+action, flag.ExitOnError)
+	dataDir := fs.String("data", "var/formserver", "Directory of stored submissions")
+	all := fs.Bool("all", false, "List approved and rejected applications too")
+	slug := fs.String("slug", "", "File name of the approved record (default from the name)")
+	records := fs.String("practitioners", directory.Dir, "Directory of practitioner records")
+	photos := fs.String("photos", "assets/images/practitioners", "Where approved photos are published (under assets/)")
+	reason := fs.String("reason", "", "Why the application was rejected, kept with it")
+	fs.Parse(args)
+
+	data, err := openLeadData(*dataDir)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	q := queue[Listing]{store: data.stores[listingsFile], noun: "listing"}
+	list, err := q.load()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	if action == "list" {
+		q.print(os.Stdout, list, *all, []string{"NAME", "PRACTICE", "REGISTRATION"}, func(l Listing) []string {
+			v := l.Values
+			return []string{v["name"], v["practice"], v["body"] + " " + v["number"]}
+		})
+		return 0
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: formserver listings %s [flags] <id>\n", action)
+		return 2
+	}
+	l, err := q.find(list, fs.Arg(0))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	switch action {
+	case "show":
+		return writeJSON(os.Stdout, l)
+	case "approve":
+		if l.Status != statusPending {
+			log.Printf("listing %s is already %s", l.ID, l.Status)
+			return 1
+		}
+		path, err := approveListing(&l, *dataDir, *records, *photos, *slug)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		if err := q.save(l); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Approved %s: wrote %s. Rebuild the site to publish /practitioners/%s/.\n", l.Values["name"], path, l.Slug)
+	case "reject":
+		if l.Status != statusPending {
+			log.Printf("listing %s is already %s", l.ID, l.Status)
+			return 1
+		}
+		l.Decide(statusRejected, *reason)
+		if err := q.save(l); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Rejected %s.\n", l.Values["name"])
+	default:
+		fmt.Fprintf(os.Stderr, "formserver listings: unknown command %q (want show, approve or reject)\n", action)
+		return 2
+	}
+	return 0
+}
+
+// approveListing writes the practitioner record for l, publishing its photo
+// under photos, and marks l approved. It returns the record's path.
+func approveListing(l *Listing, dataDir, records, photos, slug string) (string, error) {
+	p := directory.FromApplication(l.Values)
+	p.Slug = slug
+	if p.Slug == "" {
+		p.Slug = directory.Slug(p.Name)
+	}
+	if err := p.Check(); err != nil {
+		return "", fmt.Errorf("listing %s: %w", l.ID, err)
+	}
+
+	var photo string
+	if upload, ok := l.Files[directory.PhotoField]; ok {
+		photo = filepath.Join(photos, p.Slug+filepath.Ext(upload))
+		if err := copyNewFile(filepath.Join(dataDir, filepath.FromSlash(upload)), photo); err != nil {
+			return "", err
+		}
+		p.Photo = "/" + filepath.ToSlash(photo)
+	}
+	path, err := directory.Save(records, p, "Approved from listing "+l.ID)
+	if err != nil {
+		if photo != "" {
+			os.Remove(photo)
+		}
+		return "", err
+	}
+	l.Slug = p.Slug
+	l.Decide(statusApproved, "")
+	return path, nil
+}
+
+// copyNewFile copies src to dst, which must not exist yet.
+func copyNewFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"website/internal/directory"
+)
+
+// testListing is a pending application from Thandi Mokoena.
+func testListing() Listing {
+	return Listing{
+		Submission: Submission{ID: "abc123", Form: "get-listed", Values: map[string]string{
+			"name":     "Thandi Mokoena",
+			"practice": "Mokoena Tax",
+			"email":    thandi,
+			"body":     "SAIT",
+			"number":   "12345",
+			"services": "VAT Registration\nPayroll",
+			"areas":    "Cape Town, Stellenbosch",
+			"province": "Western Cape",
+		}},
+		Moderation: Moderation{Status: statusPending},
+	}
+}
+
+func TestApproveListing(t *testing.T) {
+	// Photos are published relative to the repository root
+	t.Chdir(t.TempDir())
+	const records, photos = "data/practitioners", "assets/images/practitioners"
+
+	withPhoto := testListing()
+	withPhoto.Files = map[string]string{directory.PhotoField: "uploads/abc123/photo.jpg"}
+	writeTestFile(t, filepath.FromSlash("var/uploads/abc123/photo.jpg"), "JPEG")
+	invalid := testListing()
+	invalid.Values["number"] = ""
+
+	tests := []struct {
+		name      string
+		listing   Listing
+		slug      string
+		wantSlug  string
+		wantPhoto string
+		wantErr   bool
+	}{
+		{name: "slug from the name", listing: testListing(), wantSlug: "thandi-mokoena"},
+		{name: "slug given", listing: testListing(), slug: "mokoena-tax", wantSlug: "mokoena-tax"},
+		{name: "photo published", listing: withPhoto, slug: "thandi", wantSlug: "thandi", wantPhoto: "/assets/images/practitioners/thandi.jpg"},
+		{name: "record exists", listing: withPhoto, wantErr: true}, // thandi-mokoena, from the first case
+		{name: "invalid application", listing: invalid, slug: "invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.listing
+			path, err := approveListing(&l, "var", records, photos, tt.slug)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("approved")
+				}
+				if l.Status != statusPending {
+					t.Errorf("status %s after a failed approval", l.Status)
+				}
+				if entries, _ := os.ReadDir(photos); len(entries) > 1 {
+					t.Errorf("a failed approval left its photo: %v", entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.Status != statusApproved || l.Slug != tt.wantSlug || l.Decided.IsZero() {
+				t.Errorf("listing status %s, slug %q", l.Status, l.Slug)
+			}
+			if path != filepath.Join(records, tt.wantSlug+".yaml") {
+				t.Errorf("record written to %s", path)
+			}
+			list, err := directory.Load(records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var p directory.Practitioner
+			for _, rec := range list {
+				if rec.Slug == tt.wantSlug {
+					p = rec
+				}
+			}
+			if p.Name != "Thandi Mokoena" || len(p.Services) != 2 || len(p.Areas) != 2 || p.Contact.Email != thandi || p.Photo != tt.wantPhoto {
+				t.Errorf("record = %+v", p)
+			}
+			if tt.wantPhoto != "" && !exists(filepath.FromSlash(tt.wantPhoto[1:])) {
+				t.Errorf("photo %s not published", tt.wantPhoto)
+			}
+		})
+	}
+}
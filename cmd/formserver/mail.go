@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/forms"
+)
+
+// siteInfo is the part of the builder's data/site.yaml that emails mention.
+type siteInfo struct {
+	Name    string `yaml:"name"`
+	BaseURL string `yaml:"base_url"`
+	Phone   string `yaml:"phone"`
+	Email   string `yaml:"email"`
+}
+
+// loadSiteInfo reads the site settings shared with the builder.
+func loadSiteInfo(path string) (siteInfo, error) {
+	var site siteInfo
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return site, err
+	}
+	if err := yaml.Unmarshal(raw, &site); err != nil {
+		return site, fmt.Errorf("%s: %w", path, err)
+	}
+	site.BaseURL = strings.TrimSuffix(site.BaseURL, "/")
+	return site, nil
+}
+
+// Message is an email waiting in the outbox. Bodies are rendered when the
+// message is queued, so a queued message doesn't change if templates do.
+type Message struct {
+	ID          string    `json:"id"`
+	From        string    `json:"from"`
+	To          []string  `json:"to"`
+	ReplyTo     string    `json:"reply_to,omitempty"`
+	Subject     string    `json:"subject"`
+	Text        string    `json:"text"`
+	HTML        string    `json:"html"`
+	Created     time.Time `json:"created"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Bytes encodes the message as multipart/alternative MIME.
+func (m Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	if m.ReplyTo != "" {
+		header("Reply-To", m.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", m.Created.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", m.ID, mailDomain(m.From)))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+body.Boundary()+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mailDomain is the domain of an address like "Name <user@example.com>".
+func mailDomain(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		addr = a.Address
+	}
+	if _, domain, ok := strings.Cut(addr, "@"); ok {
+		return domain
+	}
+	return "localhost"
+}
+
+// mailField is one submitted value with its label, in form order.
+type mailField struct {
+	Label string
+	Value string
+}
+
+// mailData is what the email templates see.
+type mailData struct {
+	ID       string
+	Site     siteInfo
+	Form     forms.Form
+	Fields   []mailField
+	ReplyTo  string // the submitter's address, if the form asked for one
+	Received string
+}
+
+// Email templates under components/emails, each defining a template named
+// after its file.
+const (
+	tmplNotification = "lead_notification"
+	tmplAck          = "lead_ack"
+)
+
+// Notifier turns accepted submissions into emails and queues them.
+type Notifier struct {
+	site   siteInfo
+	from   string   // sender, e.g. "SA Tax Returns <noreply@example.co.za>"
+	notify []string // who is told about new submissions
+	outbox *Outbox
+	html   *htmltemplate.Template
+	text   *texttemplate.Template
+}
+
+// NewNotifier loads the email templates from dir.
+func NewNotifier(dir string, site siteInfo, from string, notify []string, outbox *Outbox) (*Notifier, error) {
+	html, err := htmltemplate.ParseGlob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseGlob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifier{site: site, from: from, notify: notify, outbox: outbox, html: html, text: text}
+	for _, name := range []string{tmplNotification, tmplAck} {
+		if n.html.Lookup(name+".html") == nil || n.text.Lookup(name+".txt") == nil {
+			return nil, fmt.Errorf("%s: missing %s.html or %s.txt", dir, name, name)
+		}
+	}
+	return n, nil
+}
+
+// Submitted queues a notification for the form's contacts, or the site's,
+// and, when the form has an email field, an acknowledgement to the submitter.
+// Anyone can post any address, so the acknowledgement repeats nothing that
+// was submitted; otherwise the form would mail arbitrary text to arbitrary
+// people.
+func (n *Notifier) Submitted(form forms.Form, sub Submission) error {
+	data := mailData{
+		ID:       sub.ID,
+		Site:     n.site,
+		Form:     form,
+		Received: sub.Received.In(localZone).Format("2 Jan 2006 15:04 MST"),
+	}
+	for _, field := range form.Fields {
+		data.Fields = append(data.Fields, mailField{field.Label, sub.Values[field.Name]})
+		if field.InputType() == forms.TypeEmail && data.ReplyTo == "" {
+			data.ReplyTo = sub.Values[field.Name]
+		}
+	}
+
+	notify := n.notify
+	if len(form.Notify) > 0 {
+		notify = form.Notify
+	}
+	if len(notify) > 0 {
+		subject := fmt.Sprintf("New %s enquiry", form.ID)
+		if data.ReplyTo != "" {
+			subject += " from " + data.ReplyTo
+		}
+		if err := n.queue(tmplNotification, data, notify, data.ReplyTo, subject); err != nil {
+			return err
+		}
+	}
+	if data.ReplyTo != "" {
+		ack := mailData{ID: data.ID, Site: data.Site, Form: data.Form, Received: data.Received}
+		subject := "We've received your message - " + n.site.Name
+		if err := n.queue(tmplAck, ack, []string{data.ReplyTo}, "", subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) queue(name string, data mailData, to []string, replyTo, subject string) error {
+	var text, html bytes.Buffer
+	if err := n.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return err
+	}
+	if err := n.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return err
+	}
+	return n.outbox.Add(Message{
+		ID:      newID(),
+		From:    n.from,
+		To:      to,
+		ReplyTo: replyTo,
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// localZone is the time zone for dates in emails; the site is South African.
+var localZone = func() *time.Location {
+	if loc, err := time.LoadLocation("Africa/Johannesburg"); err == nil {
+		return loc
+	}
+	return time.FixedZone("SAST", 2*60*60)
+}()
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"website/internal/forms"
+)
+
+// emailTemplates are the real templates, relative to this package.
+const emailTemplates = "../../components/emails"
+
+func newTestFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	fake, err := startFakeSMTP(filepath.Join(t.TempDir(), fakeMailDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.ln.Close() })
+	return fake
+}
+
+// savedMail parses every message the fake server saved, keyed by the first
+// recipient.
+func savedMail(t *testing.T, fake *fakeSMTP) map[string]*mail.Message {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(fake.dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := make(map[string]*mail.Message)
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		to, _, _ := strings.Cut(msg.Header.Get("To"), ",")
+		msgs[to] = msg
+	}
+	return msgs
+}
+
+func TestNotifierSendsThroughFakeSMTP(t *testing.T) {
+	fake := newTestFakeSMTP(t)
+	outbox, err := OpenOutbox(filepath.Join(t.TempDir(), outboxDir), smtpConfig{Addr: fake.Addr()}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := siteInfo{Name: "SA Tax Returns", BaseURL: "https://www.example.co.za", Email: "leads@example.co.za"}
+	n, err := NewNotifier(emailTemplates, site, "SA Tax Returns <noreply@example.co.za>", []string{site.Email}, outbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.Form{ID: "contact", Page: "/contact/", Fields: []forms.Field{
+		{Name: "name", Label: "Name"},
+		{Name: "email", Label: "Email", Type: forms.TypeEmail},
+		{Name: "message", Label: "Message", Type: forms.TypeTextarea},
+	}}
+	sub := Submission{
+		ID:       newID(),
+		Form:     form.ID,
+		Values:   map[string]string{"name": "Lindiwe", "email": "lindiwe@example.com", "message": "Please help with my VAT return."},
+		Received: time.Now().UTC(),
+	}
+	if err := n.Submitted(form, sub); err != nil {
+		t.Fatal(err)
+	}
+	outbox.flush(time.Now().UTC())
+
+	if queued, _ := filepath.Glob(filepath.Join(outbox.dir, "*.json")); len(queued) != 0 {
+		t.Errorf("outbox still holds %d messages after a flush", len(queued))
+	}
+	msgs := savedMail(t, fake)
+	if len(msgs) != 2 {
+		t.Fatalf("fake SMTP saved %d messages, want the notification and the acknowledgement", len(msgs))
+	}
+
+	lead, ok := msgs[site.Email]
+	if !ok {
+		t.Fatalf("no notification to %s", site.Email)
+	}
+	if got := lead.Header.Get("Reply-To"); got != "lindiwe@example.com" {
+		t.Errorf("notification Reply-To = %q, want the submitter", got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(lead.Header.Get("Subject"))
+	if subject != "New contact enquiry from lindiwe@example.com" {
+		t.Errorf("notification subject = %q", subject)
+	}
+	body, _ := readAll(lead)
+	if !strings.Contains(body, "Please help with my VAT return.") {
+		t.Errorf("notification body lacks the message:\n%s", body)
+	}
+
+	ack, ok := msgs["lindiwe@example.com"]
+	if !ok {
+		t.Fatal("no acknowledgement to the submitter")
+	}
+	// Anyone can post any address, so nothing submitted may be echoed
+	body, _ = readAll(ack)
+	if strings.Contains(body, "VAT return") || strings.Contains(body, "Lindiwe") {
+		t.Errorf("acknowledgement repeats what was submitted:\n%s", body)
+	}
+}
+
+func TestOutboxBacksOffThenFails(t *testing.T) {
+	// A port nothing listens on: every delivery is refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := ln.Addr().String()
+	ln.Close()
+
+	outbox, err := OpenOutbox(filepath.Join(t.TempDir(), outboxDir), smtpConfig{Addr: down}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Message{ID: newID(), From: "noreply@example.co.za", To: []string{"leads@example.co.za"}, Subject: "Test"}
+	if err := outbox.Add(msg); err != nil {
+		t.Fatal(err)
+	}
+	queued := func() Message {
+		t.Helper()
+		m, err := outbox.load(outbox.path(msg.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	start := time.Now().UTC()
+	outbox.flush(start)
+	m := queued()
+	if m.Attempts != 1 || m.LastError == "" {
+		t.Fatalf("after the first failure: attempts %d, error %q", m.Attempts, m.LastError)
+	}
+	if want := start.Add(firstBackoff); !m.NextAttempt.Equal(want) {
+		t.Errorf("first retry at %s, want %s", m.NextAttempt, want)
+	}
+
+	outbox.flush(start.Add(firstBackoff / 2))
+	if m := queued(); m.Attempts != 1 {
+		t.Errorf("retried before the backoff: attempts %d", m.Attempts)
+	}
+
+	second := start.Add(firstBackoff)
+	outbox.flush(second)
+	m = queued()
+	if m.Attempts != 2 {
+		t.Fatalf("after the second failure: attempts %d", m.Attempts)
+	}
+	if want := second.Add(2 * firstBackoff); !m.NextAttempt.Equal(want) {
+		t.Errorf("second retry at %s, want the backoff doubled to %s", m.NextAttempt, want)
+	}
+
+	outbox.flush(m.NextAttempt)
+	if _, err := os.Stat(outbox.path(msg.ID)); !os.IsNotExist(err) {
+		t.Errorf("message still queued after %d attempts", outbox.maxAttempts)
+	}
+	failed, err := outbox.load(filepath.Join(outbox.dir, "failed", msg.ID+".json"))
+	if err != nil {
+		t.Fatalf("message not moved to failed/: %v", err)
+	}
+	if failed.Attempts != 3 {
+		t.Errorf("failed message records %d attempts, want 3", failed.Attempts)
+	}
+}
+
+// readAll returns the decoded text of every part of msg.
+func readAll(msg *mail.Message) (string, error) {
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		var buf bytes.Buffer
+		buf.ReadFrom(part)
+		out.Write(buf.Bytes())
+	}
+	return out.String(), nil
+}
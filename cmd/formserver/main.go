@@ -0,0 +1,179 @@
+// Command formserver receives the site's form posts, validates them against
+// the manifest written by the builder and stores them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"website/internal/directory"
+	"website/internal/forms"
+)
+
+// defaultRetention is how long leads are kept, as the privacy notice says.
+const defaultRetention = 365 * 24 * time.Hour
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export", "delete", "purge":
+			os.Exit(runAdmin(os.Args[1], os.Args[2:]))
+		case "listings":
+			os.Exit(runListings(os.Args[2:]))
+		case "reviews":
+			os.Exit(runReviews(os.Args[2:]))
+		}
+	}
+
+	addr := flag.String("addr", ":8081", "Address to listen on")
+	manifestPath := flag.String("forms", "data/forms.json", "Form manifest written by the builder")
+	dataDir := flag.String("data", "var/formserver", "Directory for stored submissions (keep out of git)")
+	siteURL := flag.String("site", "", "Base URL of the site, when it is served from another origin")
+	minFill := flag.Duration("min-fill", 3*time.Second, "Reject forms submitted sooner than this after the page loaded")
+	tokenTTL := flag.Duration("token-ttl", 2*time.Hour, "Reject form tokens older than this (0 for no limit)")
+	rateLimit := flag.Int("rate-limit", 5, "Posts allowed per IP within -rate-window (0 to disable)")
+	rateWindow := flag.Duration("rate-window", 10*time.Minute, "Window for -rate-limit")
+	trustProxy := flag.Bool("trust-proxy", false, "Use the last X-Forwarded-For entry as the client IP (only behind a single reverse proxy that appends it)")
+	siteConfig := flag.String("site-config", "data/site.yaml", "Site settings shared with the builder (name, email, base URL)")
+	emailTemplates := flag.String("email-templates", "components/emails", "Directory of email templates")
+	smtpAddr := flag.String("smtp", "", "SMTP server host:port; empty disables email")
+	smtpUser := flag.String("smtp-user", "", "SMTP username; the password is read from "+smtpPasswordEnv)
+	mailFrom := flag.String("mail-from", "", `Sender address (default "<site name> <noreply@<site domain>>")`)
+	notify := flag.String("notify", "", "Comma-separated addresses told about new submissions (default the site email)")
+	mailRetries := flag.Int("mail-retries", 10, "Attempts before a message is moved to outbox/failed")
+	fakeSMTP := flag.Bool("fake-smtp", false, "Send mail to a built-in fake SMTP server that saves it under -data/mail")
+	retention := flag.Duration("retention", defaultRetention, "Delete submissions, rejected posts and emails older than this (0 keeps them)")
+	records := flag.String("practitioners", directory.Dir, "Directory of practitioner records; -retention keeps the listings they were approved from")
+	flag.Parse()
+
+	manifest, err := forms.LoadManifest(*manifestPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := openLeadData(*dataDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *retention > 0 {
+		go purgeEvery(data, *retention, *records, time.Hour)
+	}
+
+	secret := forms.Secret()
+	if secret == nil {
+		log.Printf("Warning: %s is not set; form tokens are not checked", forms.SecretEnv)
+	}
+
+	limiter := newRateLimiter(*rateLimit, *rateWindow)
+	go func() {
+		for now := range time.Tick(*rateWindow) {
+			limiter.Prune(now)
+		}
+	}()
+
+	notifier, err := setupMail(mailConfig{
+		dataDir:   *dataDir,
+		site:      *siteConfig,
+		templates: *emailTemplates,
+		smtp:      smtpConfig{Addr: *smtpAddr, Username: *smtpUser, Password: os.Getenv(smtpPasswordEnv)},
+		fake:      *fakeSMTP,
+		from:      *mailFrom,
+		notify:    *notify,
+		retries:   *mailRetries,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := &server{
+		forms:       manifest,
+		submissions: data.stores[submissionsFile],
+		rejected:    data.stores[rejectedFile],
+		listings:    data.stores[listingsFile],
+		reviews:     data.stores[reviewsFile],
+		spam: spamConfig{
+			secret:     secret,
+			minFill:    *minFill,
+			tokenTTL:   *tokenTTL,
+			trustProxy: *trustProxy,
+		},
+		limiter:  limiter,
+		notifier: notifier,
+		dataDir:  *dataDir,
+		siteURL:  strings.TrimSuffix(*siteURL, "/"),
+	}
+	log.Printf("Form server listening on %s (%d forms)", *addr, len(manifest))
+	log.Fatal(http.ListenAndServe(*addr, srv.routes()))
+}
+
+// smtpPasswordEnv holds the SMTP password, kept out of the command line.
+const smtpPasswordEnv = "SMTP_PASSWORD"
+
+// mailConfig collects the email flags.
+type mailConfig struct {
+	dataDir   string
+	site      string
+	templates string
+	smtp      smtpConfig
+	fake      bool
+	from      string
+	notify    string
+	retries   int
+}
+
+// setupMail starts the outbox sender. It returns a nil Notifier when no SMTP
+// server is configured, and an error when there is one but no address to
+// notify.
+func setupMail(c mailConfig) (*Notifier, error) {
+	if c.fake {
+		fake, err := startFakeSMTP(filepath.Join(c.dataDir, fakeMailDir))
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Fake SMTP server on %s, saving mail to %s", fake.Addr(), fake.dir)
+		c.smtp = smtpConfig{Addr: fake.Addr()}
+	}
+	if c.smtp.Addr == "" {
+		log.Printf("Warning: no -smtp server; submissions are stored but nobody is emailed")
+		return nil, nil
+	}
+
+	site, err := loadSiteInfo(c.site)
+	if err != nil {
+		return nil, err
+	}
+	if c.from == "" {
+		host := "localhost"
+		if u, err := url.Parse(site.BaseURL); err == nil && u.Hostname() != "" {
+			host = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+		c.from = (&mail.Address{Name: site.Name, Address: "noreply@" + host}).String()
+	}
+	var notify []string
+	for _, addr := range strings.Split(c.notify, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			notify = append(notify, addr)
+		}
+	}
+	if len(notify) == 0 && site.Email != "" {
+		notify = []string{site.Email}
+	}
+	if len(notify) == 0 {
+		// Visitors would be told we got their message while nobody here is
+		return nil, fmt.Errorf("mail is on but nobody is notified of submissions: set -notify or email in %s", c.site)
+	}
+
+	outbox, err := OpenOutbox(filepath.Join(c.dataDir, outboxDir), c.smtp, c.retries)
+	if err != nil {
+		return nil, err
+	}
+	go outbox.Run(30 * time.Second)
+	return NewNotifier(c.templates, site, c.from, notify, outbox)
+}
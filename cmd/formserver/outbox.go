@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Retry schedule for messages the SMTP server didn't accept: the wait doubles
+// after every failed attempt, up to maxBackoff.
+const (
+	firstBackoff = time.Minute
+	maxBackoff   = time.Hour
+)
+
+// smtpConfig says how to reach the mail server.
+type smtpConfig struct {
+	Addr     string // host:port
+	Username string // empty for no authentication
+	Password string
+}
+
+// Send delivers raw to the server.
+func (c smtpConfig) Send(from string, to []string, raw []byte) error {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("sender %q: %w", from, err)
+	}
+	var auth smtp.Auth
+	if c.Username != "" {
+		host, _, _ := strings.Cut(c.Addr, ":")
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+	return smtp.SendMail(c.Addr, auth, sender.Address, to, raw)
+}
+
+// Outbox is a directory of messages waiting to be sent, one JSON file each.
+// A message is only removed once the SMTP server has accepted it, so mail
+// queued while the server is down goes out when it comes back, even across
+// restarts. Messages that still fail after maxAttempts move to failed/.
+type Outbox struct {
+	dir         string
+	smtp        smtpConfig
+	maxAttempts int
+	wake        chan struct{}
+}
+
+// OpenOutbox returns the outbox in dir, creating it if needed.
+func OpenOutbox(dir string, smtp smtpConfig, maxAttempts int) (*Outbox, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "failed"), 0700); err != nil {
+		return nil, err
+	}
+	return &Outbox{dir: dir, smtp: smtp, maxAttempts: maxAttempts, wake: make(chan struct{}, 1)}, nil
+}
+
+// Add queues msg and asks the sender to deliver it right away.
+func (o *Outbox) Add(msg Message) error {
+	now := time.Now().UTC()
+	msg.Created = now
+	msg.NextAttempt = now
+	if err := o.save(msg); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers queued messages until the process exits, checking for due
+// retries every interval.
+func (o *Outbox) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		o.flush(time.Now().UTC())
+		select {
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// flush tries every message that is due, oldest first.
+func (o *Outbox) flush(now time.Time) {
+	paths, err := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	if err != nil {
+		log.Printf("outbox: %v", err)
+		return
+	}
+	var due []Message
+	for _, path := range paths {
+		msg, err := o.load(path)
+		if err != nil {
+			log.Printf("outbox: %v", err)
+			continue
+		}
+		if !msg.NextAttempt.After(now) {
+			due = append(due, msg)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Created.Before(due[j].Created) })
+
+	for _, msg := range due {
+		o.deliver(msg, now)
+	}
+}
+
+func (o *Outbox) deliver(msg Message, now time.Time) {
+	raw, err := msg.Bytes()
+	if err == nil {
+		err = o.smtp.Send(msg.From, msg.To, raw)
+	}
+	if err == nil {
+		log.Printf("outbox: sent %s to %s", msg.ID, strings.Join(msg.To, ", "))
+		if err := os.Remove(o.path(msg.ID)); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		return
+	}
+
+	if _, err := os.Stat(o.path(msg.ID)); os.IsNotExist(err) {
+		return // deleted meanwhile, e.g. by "formserver delete"
+	}
+	msg.Attempts++
+	msg.LastError = err.Error()
+	if msg.Attempts >= o.maxAttempts {
+		log.Printf("outbox: giving up on %s after %d attempts: %v", msg.ID, msg.Attempts, err)
+		if err := o.save(msg); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		if err := os.Rename(o.path(msg.ID), filepath.Join(o.dir, "failed", msg.ID+".json")); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		return
+	}
+	wait := firstBackoff << (msg.Attempts - 1)
+	if wait > maxBackoff || wait <= 0 {
+		wait = maxBackoff
+	}
+	msg.NextAttempt = now.Add(wait)
+	log.Printf("outbox: sending %s failed (attempt %d), retrying in %s: %v", msg.ID, msg.Attempts, wait, err)
+	if err := o.save(msg); err != nil {
+		log.Printf("outbox: %v", err)
+	}
+}
+
+func (o *Outbox) path(id string) string {
+	return filepath.Join(o.dir, id+".json")
+}
+
+func (o *Outbox) load(path string) (Message, error) {
+	var msg Message
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return msg, fmt.Errorf("%s: %w", path, err)
+	}
+	return msg, nil
+}
+
+// save writes msg through a synced temporary file, so a crash never leaves a
+// half-written message behind.
+func (o *Outbox) save(msg Message) error {
+	raw, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(o.dir, ".msg-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), o.path(msg.ID))
+}
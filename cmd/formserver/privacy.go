@@ -0,0 +1,532 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"website/internal/directory"
+	"website/internal/reviews"
+)
+
+// Files under the data directory that hold personal information.
+const (
+	submissionsFile = "submissions.jsonl"
+	rejectedFile    = "rejected.jsonl"
+	listingsFile    = "listings.jsonl"
+	reviewsFile     = "reviews.jsonl"
+	uploadsDir      = "uploads"
+	outboxDir       = "outbox"
+	fakeMailDir     = "mail"
+)
+
+// orphanAge is how old an uploads folder without a submission must be before
+// it is removed; younger ones may belong to a post that is being stored.
+const orphanAge = time.Minute
+
+// leadData is the personal information the form server keeps in a data
+// directory: stored and rejected posts, directory applications, reviews
+// waiting for moderation, uploaded files, queued emails and,
+// when the fake SMTP server is used, saved emails. Data-subject requests and
+// the retention purge (POPIA) go through it so nothing is missed.
+type leadData struct {
+	dir    string
+	stores map[string]*Store // by file name
+}
+
+func openLeadData(dir string) (*leadData, error) {
+	d := &leadData{dir: dir, stores: make(map[string]*Store)}
+	for _, name := range []string{submissionsFile, rejectedFile, listingsFile, reviewsFile} {
+		store, err := OpenStore(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		d.stores[name] = store
+	}
+	return d, nil
+}
+
+// messageFiles lists queued and failed outbox messages.
+func (d *leadData) messageFiles() []string {
+	queued, _ := filepath.Glob(filepath.Join(d.dir, outboxDir, "*.json"))
+	failed, _ := filepath.Glob(filepath.Join(d.dir, outboxDir, "failed", "*.json"))
+	return append(queued, failed...)
+}
+
+func (d *leadData) mailFiles() []string {
+	files, _ := filepath.Glob(filepath.Join(d.dir, fakeMailDir, "*.eml"))
+	return files
+}
+
+// Export is everything held about one email address.
+type Export struct {
+	Email            string            `json:"email"`
+	Exported         time.Time         `json:"exported"`
+	Submissions      []json.RawMessage `json:"submissions"`
+	Rejected         []json.RawMessage `json:"rejected"`
+	Listings         []json.RawMessage `json:"listings"`
+	Reviews          []json.RawMessage `json:"reviews"`
+	Uploads          []string          `json:"uploads"` // files of the submissions and listings, relative to the data directory
+	Outbox           []json.RawMessage `json:"outbox"`
+	Mail             []string          `json:"mail,omitempty"`
+	Practitioners    []string          `json:"practitioners"`     // published directory records, as stored
+	Photos           []string          `json:"photos"`            // their photos, relative to the repository
+	PublishedReviews []reviews.Review  `json:"published_reviews"` // as shown on the site
+}
+
+// Export collects every record that mentions email, and what its owner has
+// published in records and published (see unpublish).
+func (d *leadData) Export(email, records, published string) (Export, error) {
+	out := Export{Email: email, Exported: time.Now().UTC()}
+	for name, dst := range map[string]*[]json.RawMessage{
+		submissionsFile: &out.Submissions,
+		rejectedFile:    &out.Rejected,
+		listingsFile:    &out.Listings,
+		reviewsFile:     &out.Reviews,
+	} {
+		*dst = []json.RawMessage{}
+		err := d.stores[name].Each(func(line []byte) error {
+			if mentionsEmail(line, email) {
+				*dst = append(*dst, json.RawMessage(bytes.Clone(line)))
+			}
+			return nil
+		})
+		if err != nil {
+			return out, err
+		}
+	}
+	out.Uploads = []string{}
+	for _, sub := range slices.Concat(out.Submissions, out.Listings) {
+		var rec Submission
+		if json.Unmarshal(sub, &rec) != nil {
+			continue
+		}
+		for _, path := range rec.Files {
+			out.Uploads = append(out.Uploads, path)
+		}
+	}
+	out.Outbox = []json.RawMessage{}
+	for _, path := range d.messageFiles() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return out, err
+		}
+		if mentionsEmail(raw, email) {
+			out.Outbox = append(out.Outbox, json.RawMessage(raw))
+		}
+	}
+	for _, path := range d.mailFiles() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return out, err
+		}
+		if emlMentions(raw, email) {
+			out.Mail = append(out.Mail, string(raw))
+		}
+	}
+
+	reviewIDs, practitioners, err := d.publishedBy(email, records)
+	if err != nil {
+		return out, err
+	}
+	out.Practitioners, out.Photos = []string{}, []string{}
+	for _, p := range practitioners {
+		raw, err := os.ReadFile(filepath.Join(records, p.Slug+".yaml"))
+		if err != nil {
+			return out, err
+		}
+		out.Practitioners = append(out.Practitioners, string(raw))
+		if photo, ok := publishedPhoto(p); ok {
+			out.Photos = append(out.Photos, filepath.ToSlash(photo))
+		}
+	}
+	all, err := reviews.Load(published)
+	if err != nil {
+		return out, err
+	}
+	out.PublishedReviews = []reviews.Review{}
+	for _, slug := range slices.Sorted(maps.Keys(all)) {
+		for _, r := range all[slug] {
+			if slices.Contains(reviewIDs, r.ID) {
+				out.PublishedReviews = append(out.PublishedReviews, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Delete removes every record that mentions email and reports how many were
+// removed from each place.
+func (d *leadData) Delete(email string) (map[string]int, error) {
+	return d.remove(
+		func(store string, line []byte) bool { return mentionsEmail(line, email) },
+		func(path string, raw []byte) bool { return mentionsEmail(raw, email) },
+		func(path string, raw []byte) bool { return emlMentions(raw, email) },
+	)
+}
+
+// Purge removes every record received before cutoff, except the approved
+// listings of practitioners still published in records: they are the
+// evidence that the practitioner agreed to be listed.
+func (d *leadData) Purge(cutoff time.Time, records string) (map[string]int, error) {
+	list, err := directory.Load(records)
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string]bool, len(list))
+	for _, p := range list {
+		listed[p.Slug] = true
+	}
+	return d.remove(
+		func(store string, line []byte) bool {
+			var rec struct {
+				Received time.Time `json:"received"`
+				Status   string    `json:"status"`
+				Slug     string    `json:"slug"`
+			}
+			if json.Unmarshal(line, &rec) != nil || rec.Received.IsZero() || !rec.Received.Before(cutoff) {
+				return false
+			}
+			return !(store == listingsFile && rec.Status == statusApproved && listed[rec.Slug])
+		},
+		func(path string, raw []byte) bool {
+			var msg Message
+			return json.Unmarshal(raw, &msg) == nil && !msg.Created.IsZero() && msg.Created.Before(cutoff)
+		},
+		func(path string, raw []byte) bool {
+			info, err := os.Stat(path)
+			return err == nil && info.ModTime().Before(cutoff)
+		},
+	)
+}
+
+func (d *leadData) remove(record func(string, []byte) bool, message, mail func(string, []byte) bool) (map[string]int, error) {
+	removed := make(map[string]int)
+	var dropped []string // IDs of removed records, whose uploads go too
+	for name, store := range d.stores {
+		n, err := store.Remove(func(line []byte) bool {
+			if !record(name, line) {
+				return false
+			}
+			var rec Submission
+			if json.Unmarshal(line, &rec) == nil && rec.ID != "" {
+				dropped = append(dropped, rec.ID)
+			}
+			return true
+		})
+		if err != nil {
+			return removed, err
+		}
+		removed[name] = n
+	}
+	for _, id := range dropped {
+		dir := filepath.Join(d.dir, uploadsDir, id)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, err
+		}
+		removed[uploadsDir]++
+	}
+	for place, match := range map[string]struct {
+		files []string
+		drop  func(string, []byte) bool
+	}{
+		outboxDir:   {d.messageFiles(), message},
+		fakeMailDir: {d.mailFiles(), mail},
+	} {
+		for _, path := range match.files {
+			raw, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue // sent meanwhile
+			}
+			if err != nil {
+				return removed, err
+			}
+			if !match.drop(path, raw) {
+				continue
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+			removed[place]++
+		}
+	}
+
+	n, err := d.removeOrphanUploads()
+	removed[uploadsDir] += n
+	return removed, err
+}
+
+// publishedBy finds what email's owner has published: the IDs of the
+// reviews they wrote that were approved, and the practitioner records in
+// records approved from their listing or carrying their address.
+func (d *leadData) publishedBy(email, records string) ([]string, []directory.Practitioner, error) {
+	var reviewIDs []string
+	slugs := make(map[string]bool)
+	for name, collect := range map[string]func([]byte){
+		reviewsFile: func(line []byte) {
+			var rec ReviewRecord
+			if json.Unmarshal(line, &rec) == nil && rec.Status == statusApproved {
+				reviewIDs = append(reviewIDs, rec.ID)
+			}
+		},
+		listingsFile: func(line []byte) {
+			var l Listing
+			if json.Unmarshal(line, &l) == nil && l.Status == statusApproved {
+				slugs[l.Slug] = true
+			}
+		},
+	} {
+		err := d.stores[name].Each(func(line []byte) error {
+			if mentionsEmail(line, email) {
+				collect(line)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	list, err := directory.Load(records)
+	if err != nil {
+		return nil, nil, err
+	}
+	var practitioners []directory.Practitioner
+	for _, p := range list {
+		if slugs[p.Slug] || strings.EqualFold(p.Contact.Email, email) {
+			practitioners = append(practitioners, p)
+		}
+	}
+	return reviewIDs, practitioners, nil
+}
+
+// publishedPhoto returns the file of p's photo if "listings approve" put it
+// under assets/; photos elsewhere aren't ours.
+func publishedPhoto(p directory.Practitioner) (string, bool) {
+	if !strings.HasPrefix(p.Photo, "/assets/") {
+		return "", false
+	}
+	return filepath.FromSlash(strings.TrimPrefix(p.Photo, "/")), true
+}
+
+// unpublish takes what email's owner has published out of the repository:
+// the reviews they wrote and their practitioner records with their photos
+// (see publishedBy). It must run before Delete, which drops the stored
+// records that say what was published. The site has to be rebuilt for the
+// pages to go.
+func (d *leadData) unpublish(email, records, published string) (map[string]int, error) {
+	removed := make(map[string]int)
+	reviewIDs, practitioners, err := d.publishedBy(email, records)
+	if err != nil {
+		return removed, err
+	}
+	for _, id := range reviewIDs {
+		found, err := reviews.Unpublish(published, id)
+		if err != nil {
+			return removed, err
+		}
+		if found {
+			removed[published]++
+		}
+	}
+	for _, p := range practitioners {
+		_, found, err := directory.Remove(records, p.Slug)
+		if err != nil {
+			return removed, err
+		}
+		if !found {
+			continue
+		}
+		removed[records]++
+		if photo, ok := publishedPhoto(p); ok {
+			err := os.Remove(photo)
+			if err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+			if err == nil {
+				removed["photos"]++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// removeOrphanUploads deletes uploaded files whose submission or listing is
+// gone.
+func (d *leadData) removeOrphanUploads() (int, error) {
+	dirs, err := os.ReadDir(filepath.Join(d.dir, uploadsDir))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	ids := make(map[string]bool)
+	for _, name := range []string{submissionsFile, listingsFile} {
+		err = d.stores[name].Each(func(line []byte) error {
+			var rec Submission
+			if json.Unmarshal(line, &rec) == nil {
+				ids[rec.ID] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	removed := 0
+	for _, dir := range dirs {
+		info, err := dir.Info()
+		if err != nil || ids[dir.Name()] || time.Since(info.ModTime()) < orphanAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(d.dir, uploadsDir, dir.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// mentionsEmail reports whether any string in the JSON document is email.
+func mentionsEmail(doc []byte, email string) bool {
+	var v any
+	if json.Unmarshal(doc, &v) != nil {
+		return false
+	}
+	return hasString(v, email)
+}
+
+func hasString(v any, email string) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), email)
+	case []any:
+		for _, item := range v {
+			if hasString(item, email) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			if hasString(item, email) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emlMentions reports whether a saved email contains the address anywhere.
+func emlMentions(raw []byte, email string) bool {
+	return bytes.Contains(bytes.ToLower(raw), []byte(strings.ToLower(email)))
+}
+
+// purgeEvery runs the retention purge now and then every interval.
+func purgeEvery(data *leadData, retention time.Duration, records string, interval time.Duration) {
+	for {
+		removed, err := data.Purge(time.Now().Add(-retention), records)
+		if err != nil {
+			log.Printf("purging leads older than %s: %v", retention, err)
+		} else if total := sum(removed); total > 0 {
+			log.Printf("purged %d records older than %s %v", total, retention, removed)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func sum(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// runAdmin runs one of the data-subject commands and returns the exit code.
+//
+//	formserver export -email someone@example.com > export.json
+//	formserver delete -email someone@example.com
+//	formserver purge -retention 8760h
+//
+// Exporting includes what the person has published in -reviews and
+// -practitioners. Deleting also takes it out of them; the site must be
+// rebuilt for it to disappear. Purging leaves published data alone, along
+// with the listings it was approved from.
+func runAdmin(cmd string, args []string) int {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	dataDir := fs.String("data", "var/formserver", "Directory of stored submissions")
+	email := fs.String("email", "", "Email address of the data subject")
+	retention := fs.Duration("retention", defaultRetention, "Remove records older than this")
+	published := fs.String("reviews", reviews.Dir, "Directory of published reviews")
+	records := fs.String("practitioners", directory.Dir, "Directory of practitioner records")
+	fs.Parse(args)
+
+	data, err := openLeadData(*dataDir)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	switch cmd {
+	case "export", "delete":
+		if *email == "" {
+			fmt.Fprintf(os.Stderr, "usage: formserver %s -email address [-data dir]\n", cmd)
+			return 2
+		}
+		if cmd == "export" {
+			out, err := data.Export(strings.TrimSpace(*email), *records, *published)
+			if err != nil {
+				log.Print(err)
+				return 1
+			}
+			return writeJSON(os.Stdout, out)
+		}
+		unpublished, err := data.unpublish(strings.TrimSpace(*email), *records, *published)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		removed, err := data.Delete(strings.TrimSpace(*email))
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		maps.Copy(removed, unpublished)
+		fmt.Printf("Deleted %d records for %s %v\n", sum(removed), *email, removed)
+	case "purge":
+		if *retention <= 0 {
+			fmt.Fprintln(os.Stderr, "formserver purge: -retention must be positive")
+			return 2
+		}
+		removed, err := data.Purge(time.Now().Add(-*retention), *records)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Purged %d records older than %s %v\n", sum(removed), *retention, removed)
+	}
+	return 0
+}
+
+func writeJSON(w io.Writer, v any) int {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
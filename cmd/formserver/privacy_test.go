@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"website/internal/directory"
+	"website/internal/reviews"
+)
+
+const (
+	thandi = "thandi@example.co.za"
+	sipho  = "sipho@example.co.za"
+)
+
+// testLeads holds a data directory with records from two people, Thandi and
+// Sipho, and what was published from them.
+type testLeads struct {
+	data               *leadData
+	records, published string
+	old, recent        time.Time
+}
+
+func newTestLeads(t *testing.T) testLeads {
+	t.Helper()
+	dir := t.TempDir()
+	data, err := openLeadData(filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := testLeads{
+		data:      data,
+		records:   filepath.Join(dir, "practitioners"),
+		published: filepath.Join(dir, "reviews"),
+		old:       time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC),
+		recent:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	appendAll := func(name string, records ...any) {
+		for _, rec := range records {
+			if err := data.stores[name].Append(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	sub := func(id, email string, received time.Time) Submission {
+		return Submission{ID: id, Form: "contact", Values: map[string]string{"name": id, "email": email}, Received: received}
+	}
+	withFile := sub("thandi-old", thandi, l.old)
+	withFile.Files = map[string]string{"document": "uploads/thandi-old/document.pdf"}
+	writeTestFile(t, filepath.Join(data.dir, "uploads", "thandi-old", "document.pdf"), "%PDF")
+	appendAll(submissionsFile, withFile, sub("thandi-recent", thandi, l.recent), sub("sipho-old", sipho, l.old))
+	appendAll(rejectedFile, Rejection{ID: "thandi-spam", Form: "contact", Reason: rejectHoneypot, Values: map[string][]string{"email": {thandi}}, Received: l.old})
+
+	listing := func(id, email, slug string) Listing {
+		l := Listing{Submission: sub(id, email, l.old), Moderation: Moderation{Status: statusApproved}, Slug: slug}
+		l.Form = "get-listed"
+		return l
+	}
+	appendAll(listingsFile,
+		listing("thandi-listing", thandi, "thandi-mokoena"),
+		listing("sipho-listing", sipho, "sipho-dlamini"), // no longer published
+	)
+	review := ReviewRecord{Submission: sub("thandi-review", thandi, l.recent), Moderation: Moderation{Status: statusApproved}, Practitioner: "sipho-dlamini"}
+	appendAll(reviewsFile, review)
+
+	_, err = directory.Save(l.records, directory.Practitioner{
+		Slug:         "thandi-mokoena",
+		Name:         "Thandi Mokoena",
+		Practice:     "Mokoena Tax",
+		Registration: directory.Registration{Body: "SAIT", Number: "12345"},
+		Services:     []string{"VAT Registration"},
+		Areas:        []string{"Cape Town"},
+		Contact:      directory.Contact{Email: thandi},
+	}, "Approved from listing thandi-listing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []reviews.Review{
+		{ID: "thandi-review", Rating: 5, Text: "Quick and clear.", Name: "Thandi M", Date: l.recent},
+		{ID: "someone-else", Rating: 4, Text: "Helpful.", Name: "Lerato", Date: l.old},
+	} {
+		if err := reviews.Publish(l.published, "sipho-dlamini", r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg := func(id string, to string, created time.Time) {
+		raw, err := json.Marshal(Message{ID: id, To: []string{to}, Subject: "Thanks", Created: created})
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(data.dir, outboxDir, id+".json"), string(raw))
+	}
+	msg("to-thandi", thandi, l.old)
+	msg("to-sipho", sipho, l.recent)
+	return l
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// storedIDs lists the IDs of the records in one of the stores.
+func storedIDs(t *testing.T, data *leadData, name string) []string {
+	t.Helper()
+	var ids []string
+	err := data.stores[name].Each(func(line []byte) error {
+		var rec Submission
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return err
+		}
+		ids = append(ids, rec.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestExport(t *testing.T) {
+	l := newTestLeads(t)
+	out, err := l.data.Export(thandi, l.records, l.published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		what string
+		got  int
+		want int
+	}{
+		{"submissions", len(out.Submissions), 2},
+		{"rejected", len(out.Rejected), 1},
+		{"listings", len(out.Listings), 1},
+		{"reviews", len(out.Reviews), 1},
+		{"uploads", len(out.Uploads), 1},
+		{"outbox", len(out.Outbox), 1},
+		{"practitioners", len(out.Practitioners), 1},
+		{"published reviews", len(out.PublishedReviews), 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: exported %d, want %d", tt.what, tt.got, tt.want)
+		}
+	}
+	if len(out.PublishedReviews) == 1 && out.PublishedReviews[0].ID != "thandi-review" {
+		t.Errorf("exported someone else's review %s", out.PublishedReviews[0].ID)
+	}
+}
+
+func TestExportUnknownEmail(t *testing.T) {
+	l := newTestLeads(t)
+	out, err := l.data.Export("nobody@example.co.za", l.records, l.published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Empty lists, not nulls, so the person can see nothing was held
+	var lists map[string]any
+	if err := json.Unmarshal(raw, &lists); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"submissions", "rejected", "listings", "reviews", "uploads", "outbox", "practitioners", "published_reviews"} {
+		if list, ok := lists[key].([]any); !ok || len(list) != 0 {
+			t.Errorf("%s = %v, want []", key, lists[key])
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	l := newTestLeads(t)
+	unpublished, err := l.data.unpublish(thandi, l.records, l.published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := l.data.Delete(thandi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpublished[l.records] != 1 || unpublished[l.published] != 1 {
+		t.Errorf("unpublished %v, want the record and the review", unpublished)
+	}
+	if removed[submissionsFile] != 2 || removed[rejectedFile] != 1 || removed[listingsFile] != 1 || removed[reviewsFile] != 1 || removed[outboxDir] != 1 || removed[uploadsDir] != 1 {
+		t.Errorf("removed %v", removed)
+	}
+
+	for name, want := range map[string][]string{
+		submissionsFile: {"sipho-old"},
+		rejectedFile:    nil,
+		listingsFile:    {"sipho-listing"},
+		reviewsFile:     nil,
+	} {
+		if got := storedIDs(t, l.data, name); !slices.Equal(got, want) {
+			t.Errorf("%s keeps %q, want %q", name, got, want)
+		}
+	}
+	if exists(filepath.Join(l.data.dir, "uploads", "thandi-old")) {
+		t.Error("uploads of a deleted submission were kept")
+	}
+	if exists(filepath.Join(l.data.dir, outboxDir, "to-thandi.json")) || !exists(filepath.Join(l.data.dir, outboxDir, "to-sipho.json")) {
+		t.Error("outbox messages not removed by recipient")
+	}
+	if exists(filepath.Join(l.records, "thandi-mokoena.yaml")) {
+		t.Error("practitioner record still published")
+	}
+	all, err := reviews.Load(l.published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all["sipho-dlamini"]) != 1 || all["sipho-dlamini"][0].ID != "someone-else" {
+		t.Errorf("published reviews left: %v", all)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	l := newTestLeads(t)
+	removed, err := l.data.Purge(l.recent.AddDate(0, -1, 0), l.records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string][]string{
+		submissionsFile: {"thandi-recent"},
+		rejectedFile:    nil,
+		// Thandi is still listed, Sipho no longer is
+		listingsFile: {"thandi-listing"},
+		reviewsFile:  {"thandi-review"},
+	} {
+		if got := storedIDs(t, l.data, name); !slices.Equal(got, want) {
+			t.Errorf("%s keeps %q, want %q", name, got, want)
+		}
+	}
+	if removed[outboxDir] != 1 || exists(filepath.Join(l.data.dir, outboxDir, "to-thandi.json")) {
+		t.Errorf("old outbox message not purged: %v", removed)
+	}
+	if removed[uploadsDir] != 1 {
+		t.Errorf("uploads removed %d, want 1", removed[uploadsDir])
+	}
+	if !exists(filepath.Join(l.records, "thandi-mokoena.yaml")) {
+		t.Error("purge removed a published record")
+	}
+}
+
+func TestMentionsEmail(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want bool
+	}{
+		{`{"values":{"email":"thandi@example.co.za"}}`, true},
+		{`{"values":{"email":" Thandi@Example.co.za "}}`, true},
+		{`{"to":["leads@example.co.za","thandi@example.co.za"]}`, true},
+		{`{"values":{"message":"write to thandi@example.co.za"}}`, false},
+		{`{"values":{"email":"thandi@example.co.za.evil"}}`, false},
+		{`not json thandi@example.co.za`, false},
+	}
+	for _, tt := range tests {
+		if got := mentionsEmail([]byte(tt.doc), thandi); got != tt.want {
+			t.Errorf("mentionsEmail(%s) = %v, want %v", tt.doc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Moderation statuses of listings and reviews.
+const (
+	statusPending  = "pending"
+	statusApproved = "approved"
+	statusRejected = "rejected"
+	statusRemoved  = "removed" // approved, then taken down again
+)
+
+// Moderation is where a posted record stands with the moderators. It is
+// embedded in the records of every moderation queue.
+type Moderation struct {
+	Status  string    `json:"status"`
+	Decided time.Time `json:"decided,omitzero"`
+	Reason  string    `json:"reason,omitempty"` // why it was rejected or removed
+}
+
+// Decide sets the status, reason and decision time.
+func (m *Moderation) Decide(status, reason string) {
+	m.Status, m.Reason, m.Decided = status, reason, time.Now().UTC()
+}
+
+func (m Moderation) status() string { return m.Status }
+
+// queued is a record in a moderation queue: a Submission with a Moderation.
+type queued interface {
+	submission() Submission
+	status() string
+}
+
+func (s Submission) submission() Submission { return s }
+
+// queue is a Store of records waiting for a moderator, like Listing and
+// ReviewRecord, one JSON object per line.
+type queue[T queued] struct {
+	store *Store
+	noun  string // what a record is called in messages, e.g. "listing"
+}
+
+// load reads every record, decided or not, in the order they were posted.
+func (q queue[T]) load() ([]T, error) {
+	var list []T
+	err := q.store.Each(func(line []byte) error {
+		var rec T
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return err
+		}
+		list = append(list, rec)
+		return nil
+	})
+	return list, err
+}
+
+// find finds the record whose ID starts with prefix.
+func (q queue[T]) find(list []T, prefix string) (T, error) {
+	var found []T
+	for _, rec := range list {
+		if strings.HasPrefix(rec.submission().ID, prefix) {
+			found = append(found, rec)
+		}
+	}
+	var zero T
+	switch len(found) {
+	case 0:
+		return zero, fmt.Errorf("no %s %q", q.noun, prefix)
+	case 1:
+		return found[0], nil
+	}
+	return zero, fmt.Errorf("%d %ss start with %q; give more of the ID", len(found), q.noun, prefix)
+}
+
+// save replaces the stored record with the same ID.
+func (q queue[T]) save(rec T) error {
+	id := rec.submission().ID
+	n, err := q.store.Update(func(line []byte) ([]byte, error) {
+		var prev T
+		if json.Unmarshal(line, &prev) != nil || prev.submission().ID != id {
+			return nil, nil
+		}
+		return json.Marshal(rec)
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%s %s was removed meanwhile", q.noun, id)
+	}
+	return err
+}
+
+// print lists the pending records, or all of them, as a table: the ID, when
+// it was received and its status, then the columns row returns.
+func (q queue[T]) print(w io.Writer, list []T, all bool, columns []string, row func(T) []string) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(append([]string{"ID", "RECEIVED", "STATUS"}, columns...), "\t"))
+	shown := 0
+	for _, rec := range list {
+		if !all && rec.status() != statusPending {
+			continue
+		}
+		sub := rec.submission()
+		cells := []string{sub.ID[:8], sub.Received.In(localZone).Format("2006-01-02 15:04"), rec.status()}
+		fmt.Fprintln(tw, strings.Join(append(cells, row(rec)...), "\t"))
+		shown++
+	}
+	tw.Flush()
+	if shown == 0 {
+		fmt.Fprintf(w, "No %ss waiting for a decision.\n", q.noun)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"website/internal/directory"
+	"website/internal/reviews"
+)
+
+// ReviewRecord is a review of a practitioner posted with the review form on
+// their profile. Like a Listing it stays in the data directory until a
+// moderator approves it, which adds it to data/reviews/ for the builder.
+type ReviewRecord struct {
+	Submission
+	Moderation
+	Practitioner string `json:"practitioner"`       // slug of the reviewed practitioner
+	Verified     bool   `json:"verified,omitempty"` // the moderator confirmed they are a client
+}
+
+// runReviews moderates reviews of practitioners and returns the exit code.
+//
+//	formserver reviews [-all]
+//	formserver reviews show <id>
+//	formserver reviews approve [-verified] <id>
+//	formserver reviews reject [-reason text] <id>
+//	formserver reviews remove [-reason text] <id>
+//
+// IDs may be shortened to any unique prefix. Flags go before the ID.
+func runReviews(args []string) int {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("reviews "+action, flag.ExitOnError)
+	dataDir := fs.String("data", "var/formserver", "Directory of stored submissions")
+	all := fs.Bool("all", false, "List approved, rejected and removed reviews too")
+	verified := fs.Bool("verified", false, "Mark the reviewer as a confirmed client")
+	records := fs.String("practitioners", directory.Dir, "Directory of practitioner records")
+	published := fs.String("reviews", reviews.Dir, "Directory of published reviews")
+	reason := fs.String("reason", "", "Why the review was rejected or removed, kept with it")
+	fs.Parse(args)
+
+	data, err := openLeadData(*dataDir)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	q := queue[ReviewRecord]{store: data.stores[reviewsFile], noun: "review"}
+	list, err := q.load()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	if action == "list" {
+		q.print(os.Stdout, list, *all, []string{"PRACTITIONER", "RATING", "NAME", "CLIENT"}, func(rec ReviewRecord) []string {
+			client := "no"
+			switch {
+			case rec.Verified:
+				client = "verified"
+			case reviews.ClaimsClient(rec.Values):
+				client = "claimed"
+			}
+			return []string{rec.Practitioner, rec.Values["rating"], rec.Values["name"], client}
+		})
+		return 0
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: formserver reviews %s [flags] <id>\n", action)
+		return 2
+	}
+	rec, err := q.find(list, fs.Arg(0))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	switch action {
+	case "show":
+		return writeJSON(os.Stdout, rec)
+	case "approve":
+		if rec.Status != statusPending {
+			log.Printf("review %s is already %s", rec.ID, rec.Status)
+			return 1
+		}
+		if err := approveReview(&rec, *records, *published, *verified); err != nil {
+			log.Print(err)
+			return 1
+		}
+		if err := q.save(rec); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Approved the review of %s by %s; rebuild the site to publish it.\n", rec.Practitioner, rec.Values["name"])
+	case "reject":
+		if rec.Status != statusPending {
+			log.Printf("review %s is already %s", rec.ID, rec.Status)
+			return 1
+		}
+		rec.Decide(statusRejected, *reason)
+		if err := q.save(rec); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Rejected the review of %s by %s; it will not be published.\n", rec.Practitioner, rec.Values["name"])
+	case "remove":
+		if rec.Status != statusApproved {
+			log.Printf("review %s is %s, not published", rec.ID, rec.Status)
+			return 1
+		}
+		found, err := reviews.Unpublish(*published, rec.ID)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		if !found {
+			log.Printf("review %s was not in %s; marking it removed", rec.ID, *published)
+		}
+		rec.Decide(statusRemoved, *reason)
+		if err := q.save(rec); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("Removed the review of %s by %s; rebuild the site to take it down.\n", rec.Practitioner, rec.Values["name"])
+	default:
+		fmt.Fprintf(os.Stderr, "formserver reviews: unknown command %q (want show, approve, reject or remove)\n", action)
+		return 2
+	}
+	return 0
+}
+
+// approveReview adds rec to the published reviews of its practitioner, who
+// must still be listed, and marks it approved.
+func approveReview(rec *ReviewRecord, records, published string, verified bool) error {
+	if _, err := os.Stat(filepath.Join(records, rec.Practitioner+".yaml")); err != nil {
+		return fmt.Errorf("review %s: practitioner %q is not listed: %w", rec.ID, rec.Practitioner, err)
+	}
+	r, err := reviews.FromSubmission(rec.ID, rec.Values, rec.Received)
+	if err != nil {
+		return err
+	}
+	r.Verified = verified
+	if err := reviews.Publish(published, rec.Practitioner, r); err != nil {
+		return err
+	}
+	rec.Verified = verified
+	rec.Decide(statusApproved, "")
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"website/internal/directory"
+	"website/internal/reviews"
+)
+
+func TestApproveReview(t *testing.T) {
+	dir := t.TempDir()
+	records, published := filepath.Join(dir, "practitioners"), filepath.Join(dir, "reviews")
+	_, err := directory.Save(records, directory.Practitioner{
+		Slug:         "thandi-mokoena",
+		Name:         "Thandi Mokoena",
+		Practice:     "Mokoena Tax",
+		Registration: directory.Registration{Body: "SAIT", Number: "12345"},
+		Services:     []string{"VAT Registration"},
+		Areas:        []string{"Cape Town"},
+	}, "Test record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := func(id, practitioner, rating string) ReviewRecord {
+		return ReviewRecord{
+			Submission: Submission{ID: id, Form: "review-" + practitioner, Received: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Values: map[string]string{
+				"rating": rating, "review": "Sorted out my VAT quickly.", "name": "Sipho D", "email": sipho,
+			}},
+			Moderation:   Moderation{Status: statusPending},
+			Practitioner: practitioner,
+		}
+	}
+	tests := []struct {
+		name     string
+		rec      ReviewRecord
+		verified bool
+		wantErr  string
+	}{
+		{"approved", record("r1", "thandi-mokoena", "5 - Excellent"), true, ""},
+		{"published twice", record("r1", "thandi-mokoena", "5 - Excellent"), false, "already published"},
+		{"practitioner no longer listed", record("r2", "sipho-dlamini", "4 - Good"), false, "is not listed"},
+		{"forged rating", record("r3", "thandi-mokoena", "9 - Amazing"), false, "rating 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tt.rec
+			err := approveReview(&rec, records, published, tt.verified)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				if rec.Status != statusPending {
+					t.Errorf("status %s after a failed approval", rec.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Status != statusApproved || rec.Verified != tt.verified {
+				t.Errorf("record status %s, verified %v", rec.Status, rec.Verified)
+			}
+		})
+	}
+
+	all, err := reviews.Load(published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := all["thandi-mokoena"]
+	if len(list) != 1 || list[0].ID != "r1" || list[0].Rating != 5 || !list[0].Verified || list[0].Name != "Sipho D" {
+		t.Errorf("published %+v", list)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	store, err := OpenStore(t.TempDir(), reviewsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queue[ReviewRecord]{store: store, noun: "review"}
+	received := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"aa11aa11aa11", "aa22aa22aa22", "bb33bb33bb33"} {
+		rec := ReviewRecord{Submission: Submission{ID: id, Values: map[string]string{"name": "Reviewer " + id[:4]}, Received: received}, Moderation: Moderation{Status: statusPending}}
+		if err := store.Append(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := q.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   string // ID found, or substring of the error
+	}{
+		{"aa11", "aa11aa11aa11"},
+		{"bb", "bb33bb33bb33"},
+		{"aa", `2 reviews start with "aa"`},
+		{"cc", `no review "cc"`},
+	}
+	for _, tt := range tests {
+		rec, err := q.find(list, tt.prefix)
+		got := rec.ID
+		if err != nil {
+			got = err.Error()
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("find(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+
+	rec, _ := q.find(list, "aa22")
+	rec.Decide(statusRejected, "spam")
+	if err := q.save(rec); err != nil {
+		t.Fatal(err)
+	}
+	if list, err = q.load(); err != nil {
+		t.Fatal(err)
+	}
+	if list[1].Status != statusRejected || list[1].Reason != "spam" || list[0].Status != statusPending || len(list) != 3 {
+		t.Errorf("after save: %+v", list)
+	}
+
+	var out strings.Builder
+	q.print(&out, list, false, []string{"NAME"}, func(r ReviewRecord) []string { return []string{r.Values["name"]} })
+	if s := out.String(); !strings.Contains(s, "aa11aa11") || strings.Contains(s, "aa22aa22") || !strings.Contains(s, "Reviewer bb33") {
+		t.Errorf("pending list:\n%s", s)
+	}
+	out.Reset()
+	q.print(&out, nil, false, nil, nil)
+	if !strings.Contains(out.String(), "No reviews waiting for a decision.") {
+		t.Errorf("empty list:\n%s", out.String())
+	}
+
+	gone := ReviewRecord{Submission: Submission{ID: "deleted"}}
+	if err := q.save(gone); err == nil || !strings.Contains(err.Error(), "was removed meanwhile") {
+		t.Errorf("saving a deleted record: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons a post is rejected as spam, recorded in rejected.jsonl.
+const (
+	rejectRateLimited = "rate_limited"
+	rejectHoneypot    = "honeypot"
+	rejectToken       = "token"
+)
+
+// Rejection is a post turned away by the spam checks, kept for review.
+type Rejection struct {
+	ID       string              `json:"id"`
+	Form     string              `json:"form"`
+	Reason   string              `json:"reason"`
+	Detail   string              `json:"detail,omitempty"`
+	IP       string              `json:"ip"`
+	Values   map[string][]string `json:"values,omitempty"`
+	Received time.Time           `json:"received"`
+}
+
+// spamConfig holds the tunable spam checks.
+type spamConfig struct {
+	secret     []byte        // token signing key; nil disables token checks
+	minFill    time.Duration // fastest plausible time to fill in a form
+	tokenTTL   time.Duration // how long a token stays valid, 0 for no limit
+	trustProxy bool          // take the client IP from X-Forwarded-For
+}
+
+// rateLimiter allows at most limit posts per IP in any window.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+	denied map[string]bool // IPs over the limit whose first denied post was reported
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, hits: make(map[string][]time.Time), denied: make(map[string]bool)}
+}
+
+// Allow records a post from ip and reports whether it is within the limit.
+// For a post over the limit, first reports whether it is the first since ip
+// went over: only that one is worth logging, or a flood would fill the log.
+// A limit of zero disables rate limiting.
+func (l *rateLimiter) Allow(ip string, now time.Time) (ok, first bool) {
+	if l.limit <= 0 {
+		return true, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := l.hits[ip][:0]
+	for _, t := range l.hits[ip] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.hits[ip] = recent
+		first = !l.denied[ip]
+		l.denied[ip] = true
+		return false, first
+	}
+	l.hits[ip] = append(recent, now)
+	delete(l.denied, ip)
+	return true, false
+}
+
+// Prune forgets IPs with no posts inside the window.
+func (l *rateLimiter) Prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ip, hits := range l.hits {
+		if len(hits) == 0 || now.Sub(hits[len(hits)-1]) >= l.window {
+			delete(l.hits, ip)
+			delete(l.denied, ip)
+		}
+	}
+}
+
+// clientIP is the address the post came from. Behind a reverse proxy it is
+// the last X-Forwarded-For entry, the one our proxy appended: earlier
+// entries come from the client and can be anything.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+			entries := strings.Split(fwd[len(fwd)-1], ",")
+			if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
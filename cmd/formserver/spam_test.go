@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"website/internal/forms"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute)
+	tests := []struct {
+		ip        string
+		after     time.Duration // since start
+		ok, first bool
+	}{
+		{"10.0.0.1", 0, true, false},
+		{"10.0.0.1", 10 * time.Second, true, false},
+		{"10.0.0.1", 20 * time.Second, false, true},
+		{"10.0.0.1", 30 * time.Second, false, false}, // still over: not logged again
+		{"10.0.0.2", 30 * time.Second, true, false},  // other IPs are counted apart
+		{"10.0.0.1", 61 * time.Second, true, false},  // the first post left the window
+		{"10.0.0.1", 62 * time.Second, false, true},  // over again: logged again
+	}
+	for i, tt := range tests {
+		ok, first := l.Allow(tt.ip, start.Add(tt.after))
+		if ok != tt.ok || first != tt.first {
+			t.Errorf("post %d from %s: Allow = %v, %v; want %v, %v", i, tt.ip, ok, first, tt.ok, tt.first)
+		}
+	}
+
+	l.Prune(start.Add(90 * time.Second))
+	if _, ok := l.hits["10.0.0.2"]; ok {
+		t.Error("Prune kept an IP with no recent posts")
+	}
+	if _, ok := l.hits["10.0.0.1"]; !ok {
+		t.Error("Prune dropped an IP with recent posts")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := newRateLimiter(0, time.Minute)
+	for range 10 {
+		if ok, _ := l.Allow("10.0.0.1", time.Now()); !ok {
+			t.Fatal("a zero limit turned a post away")
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remote     string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{"direct", "203.0.113.7:51234", nil, false, "203.0.113.7"},
+		{"ipv6", "[2001:db8::1]:443", nil, false, "2001:db8::1"},
+		{"no port", "203.0.113.7", nil, false, "203.0.113.7"},
+		{"forwarded but proxy not trusted", "10.0.0.1:80", []string{"203.0.113.7"}, false, "10.0.0.1"},
+		{"behind proxy", "10.0.0.1:80", []string{"203.0.113.7"}, true, "203.0.113.7"},
+		{"spoofed entries before the proxy's", "10.0.0.1:80", []string{"1.2.3.4, 203.0.113.7"}, true, "203.0.113.7"},
+		{"several headers", "10.0.0.1:80", []string{"1.2.3.4", "5.6.7.8, 203.0.113.7"}, true, "203.0.113.7"},
+		{"trusted proxy without header", "10.0.0.1:80", nil, true, "10.0.0.1"},
+		{"empty last entry", "10.0.0.1:80", []string{"1.2.3.4, "}, true, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/forms/contact", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := clientIP(r, tt.trustProxy); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleToken(t *testing.T) {
+	secret := []byte("s3cret")
+	s := &server{forms: forms.Manifest{"contact": {ID: "contact"}}, spam: spamConfig{secret: secret}}
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/forms/contact/token", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status %d, Cache-Control %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	var body struct{ Token string }
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if err := forms.CheckToken(secret, "contact", body.Token, time.Now().Add(5*time.Second), 3*time.Second, time.Hour); err != nil {
+		t.Errorf("issued token: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/forms/unknown/token", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown form: status %d, want 404", rec.Code)
+	}
+	s.spam.secret = nil
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/forms/contact/token", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("without a secret: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"website/internal/forms"
+)
+
+// Submission is one accepted form post.
+type Submission struct {
+	ID       string            `json:"id"`
+	Form     string            `json:"form"`
+	Values   map[string]string `json:"values"`
+	Files    map[string]string `json:"files,omitempty"` // file field name -> path under the data directory
+	Consent  *ConsentRecord    `json:"consent,omitempty"`
+	Received time.Time         `json:"received"`
+}
+
+// ConsentRecord is the privacy notice a visitor accepted with a submission.
+type ConsentRecord struct {
+	Version string    `json:"version"`
+	Given   time.Time `json:"given"`
+}
+
+// Store appends records to a JSON Lines file. Every write is synced before
+// returning, so an accepted submission survives a crash. Writers hold a lock
+// file, so the admin commands can rewrite the file while the server runs.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// lockTimeout is how long to wait for another process's lock, and staleLock
+// the age at which a leftover lock from a crashed process is broken.
+const (
+	lockTimeout = 10 * time.Second
+	staleLock   = time.Minute
+)
+
+// lock takes the store's lock file, shared with other processes.
+func (s *Store) lock() (unlock func(), err error) {
+	s.mu.Lock()
+	name := s.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(name)
+				s.mu.Unlock()
+			}, nil
+		}
+		if !os.IsExist(err) {
+			s.mu.Unlock()
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("%s is locked by another process", s.path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// OpenStore returns a store writing to name inside dir, creating dir if needed.
+func OpenStore(dir, name string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{path: filepath.Join(dir, name)}, nil
+}
+
+// Append writes v as one line.
+func (s *Store) Append(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Each calls fn with every record, in the order they were written.
+func (s *Store) Each(fn func(line []byte) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.each(fn)
+}
+
+func (s *Store) each(fn func(line []byte) error) error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Remove deletes every record for which drop returns true and reports how
+// many were removed.
+func (s *Store) Remove(drop func(line []byte) bool) (int, error) {
+	return s.rewrite(func(line []byte) ([]byte, bool) {
+		if drop(line) {
+			return nil, true
+		}
+		return line, false
+	})
+}
+
+// Update replaces every record for which edit returns a new line, and
+// reports how many were replaced. edit returns nil to keep a record as is.
+func (s *Store) Update(edit func(line []byte) ([]byte, error)) (int, error) {
+	var editErr error
+	n, err := s.rewrite(func(line []byte) ([]byte, bool) {
+		if editErr != nil {
+			return line, false
+		}
+		out, err := edit(line)
+		if err != nil {
+			editErr = err
+			return line, false
+		}
+		if out == nil {
+			return line, false
+		}
+		return out, true
+	})
+	if editErr != nil {
+		return 0, editErr
+	}
+	return n, err
+}
+
+// rewrite passes every record through fn, which returns the record to keep
+// (nil to drop it) and whether it changed. When something changed, the file
+// is rewritten through a synced temporary file, so a crash leaves either the
+// old or the new contents.
+func (s *Store) rewrite(fn func(line []byte) ([]byte, bool)) (int, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var kept bytes.Buffer
+	changed := 0
+	err = s.each(func(line []byte) error {
+		out, ok := fn(line)
+		if ok {
+			changed++
+		}
+		if out != nil {
+			kept.Write(out)
+			kept.WriteByte('\n')
+		}
+		return nil
+	})
+	if err != nil || changed == 0 {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), ".store-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(kept.Bytes()); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(f.Name(), 0600); err != nil {
+		return 0, err
+	}
+	return changed, os.Rename(f.Name(), s.path)
+}
+
+// saveUploads stores a submission's files under uploads/<submission ID>/ in
+// dataDir and returns their paths relative to dataDir. Files are named after
+// their field, so a visitor's file name never becomes a path.
+func saveUploads(dataDir, id string, form forms.Form, files map[string][]*multipart.FileHeader) (map[string]string, error) {
+	dir := filepath.Join(dataDir, uploadsDir, id)
+	saved := make(map[string]string)
+	for _, field := range form.Fields {
+		if field.InputType() != forms.TypeFile || len(files[field.Name]) == 0 {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		fh := files[field.Name][0]
+		name := field.Name + strings.ToLower(filepath.Ext(fh.Filename))
+		if err := saveUpload(fh, filepath.Join(dir, name)); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		saved[field.Name] = filepath.ToSlash(filepath.Join(uploadsDir, id, name))
+	}
+	return saved, nil
+}
+
+func saveUpload(fh *multipart.FileHeader, path string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// newID returns a random identifier for a record.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"website/internal/forms"
+)
+
+// multipartFiles posts files, by field name and file name, and returns what
+// the server sees.
+func multipartFiles(t *testing.T, files map[string][2]string) map[string][]*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for field, file := range files {
+		part, err := w.CreateFormFile(field, file[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(file[1]))
+	}
+	w.Close()
+	r := httptest.NewRequest(http.MethodPost, "/forms/vat", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return r.MultipartForm.File
+}
+
+func TestSaveUploads(t *testing.T) {
+	dir := t.TempDir()
+	form := forms.Form{ID: "vat", Fields: []forms.Field{
+		{Name: "name", Label: "Name"},
+		{Name: "document", Label: "Document", Type: forms.TypeFile},
+		{Name: "photo", Label: "Photo", Type: forms.TypeFile},
+	}}
+	files := multipartFiles(t, map[string][2]string{
+		"document": {"../../Statement.PDF", "%PDF"},
+		"extra":    {"evil.sh", "rm -rf /"}, // not a field of the form
+	})
+	saved, err := saveUploads(dir, "abc123", form, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"document": "uploads/abc123/document.pdf"}; !maps.Equal(saved, want) {
+		t.Errorf("saved %q, want %q", saved, want)
+	}
+	if raw, err := os.ReadFile(filepath.Join(dir, "uploads", "abc123", "document.pdf")); err != nil || string(raw) != "%PDF" {
+		t.Errorf("saved file %q, %v", raw, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "uploads", "abc123"))
+	if len(entries) != 1 {
+		t.Errorf("upload directory has %d files, want 1", len(entries))
+	}
+}
@@ -1,3 +1,8 @@
 module website
 
 go 1.24.6
+
+require (
+	github.com/yuin/goldmark v1.7.16
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,142 @@
+package directory
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/forms"
+)
+
+// Provinces are South Africa's provinces, offered on the application form.
+var Provinces = []string{
+	"Eastern Cape", "Free State", "Gauteng", "KwaZulu-Natal", "Limpopo",
+	"Mpumalanga", "North West", "Northern Cape", "Western Cape",
+}
+
+// ApplicationFields are the fields of the "Get Listed" form. The form server
+// stores applications for review, and an approved one becomes a record
+// through FromApplication, so the field names here are the contract between
+// the two.
+func ApplicationFields() []forms.Field {
+	return []forms.Field{
+		{Name: "name", Label: "Full name", Required: true},
+		{Name: "practice", Label: "Practice name", Required: true},
+		{Name: "email", Label: "Email", Type: forms.TypeEmail, Required: true, Help: "Client enquiries from your profile are sent here."},
+		{Name: "phone", Label: "Phone", Type: forms.TypePhone},
+		{Name: "website", Label: "Website", Placeholder: "https://"},
+		{Name: "body", Label: "Professional body", Type: forms.TypeSelect, Required: true, Options: Bodies},
+		{Name: "number", Label: "Registration number", Required: true, Help: "We verify it with your professional body before listing you."},
+		{Name: "services", Label: "Services", Type: forms.TypeTextarea, Required: true, MaxLength: 1000, Help: "One per line, e.g. VAT Registration."},
+		{Name: "areas", Label: "Cities and towns you serve", Required: true, Help: "Separate them with commas; the first is shown in your page title."},
+		{Name: "province", Label: "Province", Type: forms.TypeSelect, Required: true, Options: Provinces},
+		{Name: "languages", Label: "Languages", Placeholder: "English, Afrikaans", Help: "Separate them with commas."},
+		{Name: "bio", Label: "About your practice", Type: forms.TypeTextarea, MaxLength: 1000},
+		{Name: "photo", Label: "Photo", Type: forms.TypeFile, Accept: []string{".jpg", ".jpeg", ".png"}, MaxMB: 2, Help: "Optional. A square head-and-shoulders photo works best."},
+	}
+}
+
+// PhotoField is the application field holding the practitioner's photo. The
+// form server stores the file; whoever approves the application publishes it
+// and sets Photo.
+const PhotoField = "photo"
+
+// FromApplication turns the values of an application into a record. The
+// caller sets Slug and Photo and should Check the result.
+func FromApplication(values map[string]string) Practitioner {
+	return Practitioner{
+		Name:         strings.TrimSpace(values["name"]),
+		Practice:     strings.TrimSpace(values["practice"]),
+		Registration: Registration{Body: values["body"], Number: strings.TrimSpace(values["number"])},
+		Services:     splitList(values["services"]),
+		Areas:        splitList(values["areas"]),
+		Provinces:    splitList(values["province"]),
+		Languages:    splitList(values["languages"]),
+		Bio:          strings.TrimSpace(values["bio"]),
+		Contact: Contact{
+			Phone:   strings.TrimSpace(values["phone"]),
+			Email:   strings.TrimSpace(values["email"]),
+			Website: strings.TrimSpace(values["website"]),
+		},
+	}
+}
+
+// splitList splits a list typed one per line or separated by commas,
+// dropping blanks and repeats.
+func splitList(s string) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == ';' }) {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[strings.ToLower(item)] {
+			continue
+		}
+		seen[strings.ToLower(item)] = true
+		list = append(list, item)
+	}
+	return list
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug suggests a file name for a practitioner: "Thandi Mokoena" becomes
+// "thandi-mokoena".
+func Slug(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Remove deletes the record with slug from dir and returns it, so the caller
+// can remove its photo too. It reports whether the record existed.
+func Remove(dir, slug string) (Practitioner, bool, error) {
+	path := filepath.Join(dir, slug+".yaml")
+	p, err := loadFile(path)
+	if os.IsNotExist(err) {
+		return p, false, nil
+	}
+	if err != nil {
+		return p, false, err
+	}
+	return p, true, os.Remove(path)
+}
+
+// Save writes p to <dir>/<slug>.yaml, noting where the record came from.
+// It never overwrites an existing record.
+func Save(dir string, p Practitioner, note string) (string, error) {
+	if err := p.Check(); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s on %s.\n", note, time.Now().Format("2006-01-02"))
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, p.Slug+".yaml")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return "", fmt.Errorf("%s already exists; choose another slug", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
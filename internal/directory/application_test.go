@@ -0,0 +1,63 @@
+package directory
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"Cape Town", []string{"Cape Town"}},
+		{"Cape Town, Stellenbosch; Paarl", []string{"Cape Town", "Stellenbosch", "Paarl"}},
+		{"VAT Registration\r\nPayroll\n\n", []string{"VAT Registration", "Payroll"}},
+		{"Payroll, payroll, PAYROLL", []string{"Payroll"}},
+		{" , ;\n", nil},
+	}
+	for _, tt := range tests {
+		if got := splitList(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Thandi Mokoena":              "thandi-mokoena",
+		"  Jean-Pierre  du Toit ":     "jean-pierre-du-toit",
+		"Company Registration (CIPC)": "company-registration-cipc",
+		"Zoë":                         "zo",
+		"!!":                          "",
+	} {
+		if got := Slug(name); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFromApplication(t *testing.T) {
+	p := FromApplication(map[string]string{
+		"name":      " Thandi Mokoena ",
+		"practice":  "Mokoena Tax",
+		"email":     "thandi@example.co.za",
+		"body":      "SAIT",
+		"number":    "12345",
+		"services":  "VAT Registration\nPayroll",
+		"areas":     "Cape Town, Stellenbosch",
+		"province":  "Western Cape",
+		"languages": "English, isiXhosa",
+	})
+	p.Slug = Slug(p.Name)
+	if err := p.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Thandi Mokoena" || p.Registration.String() != "SAIT 12345" || p.Contact.Email != "thandi@example.co.za" {
+		t.Errorf("record = %+v", p)
+	}
+	if !slices.Equal(p.Provinces, []string{"Western Cape"}) || !slices.Equal(p.Languages, []string{"English", "isiXhosa"}) {
+		t.Errorf("provinces %q, languages %q", p.Provinces, p.Languages)
+	}
+}
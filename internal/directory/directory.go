@@ -0,0 +1,150 @@
+// Package directory holds the practitioner directory. Each practitioner is a
+// YAML file in data/practitioners/, named after the practitioner's slug; the
+// builder generates a profile page for each one.
+package directory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is where practitioner records live, relative to the repository root.
+const Dir = "data/practitioners"
+
+// Bodies are the professional bodies a listed practitioner must be
+// registered with.
+var Bodies = []string{"SAIT", "SAICA"}
+
+// Practitioner is a tax practitioner listed in the directory.
+type Practitioner struct {
+	Slug         string       `yaml:"-"` // from the file name, e.g. "thandi-mokoena"
+	Name         string       `yaml:"name"`
+	Practice     string       `yaml:"practice"`
+	Registration Registration `yaml:"registration"`
+	Services     []string     `yaml:"services"`  // e.g. "VAT Registration"
+	Areas        []string     `yaml:"areas"`     // cities and towns served
+	Provinces    []string     `yaml:"provinces"` // e.g. "Western Cape"
+	Languages    []string     `yaml:"languages"` // e.g. "English", "isiXhosa"
+	Photo        string       `yaml:"photo"`     // image under /assets
+	Bio          string       `yaml:"bio"`
+	Contact      Contact      `yaml:"contact"`
+}
+
+// Registration is a practitioner's membership of a professional body.
+type Registration struct {
+	Body   string `yaml:"body"` // one of Bodies
+	Number string `yaml:"number"`
+}
+
+// String reads like "SAIT 12345".
+func (r Registration) String() string {
+	return r.Body + " " + r.Number
+}
+
+// Contact is how clients reach a practitioner. Empty values are not shown.
+type Contact struct {
+	Phone   string `yaml:"phone"`
+	Email   string `yaml:"email"` // also receives enquiries from the profile page
+	Website string `yaml:"website"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Check reports problems with the record.
+func (p Practitioner) Check() error {
+	var missing []string
+	for _, f := range []struct {
+		name  string
+		empty bool
+	}{
+		{"name", p.Name == ""},
+		{"practice", p.Practice == ""},
+		{"registration.number", p.Registration.Number == ""},
+		{"services", len(p.Services) == 0},
+		{"areas", len(p.Areas) == 0},
+	} {
+		if f.empty {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	if !slugPattern.MatchString(p.Slug) {
+		return fmt.Errorf("file name %q must be lowercase words joined by hyphens", p.Slug)
+	}
+	if !slices.Contains(Bodies, p.Registration.Body) {
+		return fmt.Errorf("registration.body %q must be one of %s", p.Registration.Body, strings.Join(Bodies, ", "))
+	}
+	return nil
+}
+
+// Terms lists the practitioner's taxonomy terms by taxonomy name, for the
+// builder's service, city and province pages.
+func (p Practitioner) Terms() map[string][]string {
+	return map[string][]string{
+		"service":  p.Services,
+		"city":     p.Areas,
+		"province": p.Provinces,
+	}
+}
+
+// FirstName is the first word of the name, for buttons like "Contact Thandi".
+func (p Practitioner) FirstName() string {
+	first, _, _ := strings.Cut(p.Name, " ")
+	return first
+}
+
+// Initial is the first letter of the name, for the photo fallback.
+func (p Practitioner) Initial() string {
+	for _, r := range p.Name {
+		return strings.ToUpper(string(r))
+	}
+	return ""
+}
+
+// Load reads every practitioner in dir, sorted by name. A missing directory
+// is an empty directory. Files starting with "_" are skipped, so examples
+// can live next to real records.
+func Load(dir string) ([]Practitioner, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var list []Practitioner
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(path), "_") {
+			continue
+		}
+		p, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func loadFile(path string) (Practitioner, error) {
+	var p Practitioner
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return p, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Slug = strings.TrimSuffix(filepath.Base(path), ".yaml")
+	if err := p.Check(); err != nil {
+		return p, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,345 @@
+// Package forms holds the form definitions shared by the builder, which
+// renders them, and the form server, which validates submissions against
+// them. The builder writes every form it renders to a manifest so both sides
+// always agree on the fields.
+package forms
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field types.
+const (
+	TypeText     = "text"
+	TypeEmail    = "email"
+	TypePhone    = "phone"
+	TypeSelect   = "select"
+	TypeTextarea = "textarea"
+	TypeCheckbox = "checkbox"
+	TypeFile     = "file"
+)
+
+// Checked is the value a ticked checkbox posts.
+const Checked = "yes"
+
+// Default limits, used when a field doesn't set its own.
+const (
+	defaultMaxLength         = 200
+	defaultTextareaMaxLength = 5000
+	defaultMaxMB             = 5
+)
+
+// defaultAccept are the file types a file field takes when it doesn't list
+// its own: documents and photos of documents.
+var defaultAccept = []string{".pdf", ".jpg", ".jpeg", ".png"}
+
+// Field is one input of a form.
+type Field struct {
+	Name        string   `yaml:"name" json:"name"`
+	Label       string   `yaml:"label" json:"label"`
+	Type        string   `yaml:"type" json:"type"` // text, email, phone, select, textarea, checkbox or file; defaults to text
+	Placeholder string   `yaml:"placeholder" json:"placeholder,omitempty"`
+	Help        string   `yaml:"help" json:"help,omitempty"` // hint shown under the input
+	Required    bool     `yaml:"required" json:"required"`
+	MaxLength   int      `yaml:"max_length" json:"max_length,omitempty"`
+	Options     []string `yaml:"options" json:"options,omitempty"` // choices of a select
+	Accept      []string `yaml:"accept" json:"accept,omitempty"`   // file extensions, e.g. [".pdf"]
+	MaxMB       int      `yaml:"max_mb" json:"max_mb,omitempty"`   // file size limit in megabytes
+}
+
+// InputType is the field type with the default applied.
+func (f Field) InputType() string {
+	if f.Type == "" {
+		return TypeText
+	}
+	return f.Type
+}
+
+// HTMLType is the type attribute of the field's <input>.
+func (f Field) HTMLType() string {
+	if f.InputType() == TypePhone {
+		return "tel"
+	}
+	return f.InputType()
+}
+
+// Autocomplete is the browser autofill hint for the field, if there is one.
+func (f Field) Autocomplete() string {
+	switch {
+	case f.InputType() == TypeEmail:
+		return "email"
+	case f.InputType() == TypePhone:
+		return "tel"
+	case f.Name == "name":
+		return "name"
+	}
+	return ""
+}
+
+// AcceptList is the file extensions a file field takes.
+func (f Field) AcceptList() []string {
+	if len(f.Accept) > 0 {
+		return f.Accept
+	}
+	return defaultAccept
+}
+
+// MaxBytes is the largest file a file field takes.
+func (f Field) MaxBytes() int64 {
+	mb := f.MaxMB
+	if mb <= 0 {
+		mb = defaultMaxMB
+	}
+	return int64(mb) << 20
+}
+
+// SizeLimit is MaxBytes for people, e.g. "5 MB".
+func (f Field) SizeLimit() string {
+	return fmt.Sprintf("%d MB", f.MaxBytes()>>20)
+}
+
+// Limit is the maximum accepted length in characters.
+func (f Field) Limit() int {
+	switch {
+	case f.MaxLength > 0:
+		return f.MaxLength
+	case f.InputType() == TypeTextarea:
+		return defaultTextareaMaxLength
+	default:
+		return defaultMaxLength
+	}
+}
+
+// ConsentField is the checkbox a visitor ticks to accept the privacy notice.
+const ConsentField = "privacy_consent"
+
+// Consent is the privacy notice a form asks visitors to accept (POPIA).
+type Consent struct {
+	Version string `json:"version"` // version of the notice the form links to
+	Notice  string `json:"notice"`  // URL of the privacy notice
+}
+
+// Form kinds decide what the form server does with a post.
+const (
+	KindLead    = ""        // an enquiry, stored with the other submissions
+	KindListing = "listing" // an application to the practitioner directory, stored for review
+	KindReview  = "review"  // a review of the practitioner named by Subject, stored for moderation
+)
+
+// Form is a form as rendered on the site.
+type Form struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind,omitempty"`    // one of the Kind constants
+	Subject string   `json:"subject,omitempty"` // what the form is about, e.g. the slug of a reviewed practitioner
+	Page    string   `json:"page"`              // URL of the page the form is on
+	Success string   `json:"success"`           // URL to redirect to after a successful submission
+	Fields  []Field  `json:"fields"`
+	Consent *Consent `json:"consent,omitempty"` // nil when the form doesn't ask for consent
+	Notify  []string `json:"notify,omitempty"`  // who is emailed about submissions; empty for the form server's default
+}
+
+// Check reports problems with the definition itself.
+func (f Form) Check() error {
+	if f.ID == "" {
+		return fmt.Errorf("form has no id")
+	}
+	switch f.Kind {
+	case KindLead, KindListing:
+	case KindReview:
+		if f.Subject == "" {
+			return fmt.Errorf("form %s: a review form needs a subject", f.ID)
+		}
+	default:
+		return fmt.Errorf("form %s: unknown kind %q", f.ID, f.Kind)
+	}
+	seen := make(map[string]bool)
+	for _, field := range f.Fields {
+		if field.Name == "" {
+			return fmt.Errorf("form %s: field %q has no name", f.ID, field.Label)
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("form %s: duplicate field %q", f.ID, field.Name)
+		}
+		if field.Name == HoneypotField || field.Name == TokenField || field.Name == ConsentField {
+			return fmt.Errorf("form %s: field name %q is reserved", f.ID, field.Name)
+		}
+		seen[field.Name] = true
+		switch field.InputType() {
+		case TypeText, TypeEmail, TypePhone, TypeTextarea, TypeCheckbox:
+		case TypeSelect:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("form %s: select %s has no options", f.ID, field.Name)
+			}
+		case TypeFile:
+			for _, ext := range field.Accept {
+				if !strings.HasPrefix(ext, ".") {
+					return fmt.Errorf("form %s: field %s accepts %q, want an extension like \".pdf\"", f.ID, field.Name, ext)
+				}
+			}
+		default:
+			return fmt.Errorf("form %s: field %s has unknown type %q", f.ID, field.Name, field.Type)
+		}
+	}
+	for _, addr := range f.Notify {
+		if !validEmail(addr) {
+			return fmt.Errorf("form %s: notify address %q is not a valid email address", f.ID, addr)
+		}
+	}
+	if f.Consent != nil && f.Consent.Version == "" {
+		return fmt.Errorf("form %s: consent has no privacy notice version", f.ID)
+	}
+	return nil
+}
+
+// HasFiles reports whether the form uploads files, and so must be posted as
+// multipart/form-data.
+func (f Form) HasFiles() bool {
+	return slices.ContainsFunc(f.Fields, func(field Field) bool { return field.InputType() == TypeFile })
+}
+
+// MaxUploadBytes is the most the form's files may add up to.
+func (f Form) MaxUploadBytes() int64 {
+	var total int64
+	for _, field := range f.Fields {
+		if field.InputType() == TypeFile {
+			total += field.MaxBytes()
+		}
+	}
+	return total
+}
+
+// Validate checks submitted values and files against the form. It returns
+// the trimmed values of the form's fields, ignoring anything else that was
+// posted, and a message per invalid field. A file field's value is the
+// uploaded file's name; files may be nil for forms without file fields. A
+// missing consent is reported under ConsentField.
+func (f Form) Validate(values url.Values, files map[string][]*multipart.FileHeader) (clean map[string]string, problems map[string]string) {
+	clean = make(map[string]string, len(f.Fields))
+	problems = make(map[string]string)
+	for _, field := range f.Fields {
+		if field.InputType() == TypeFile {
+			var file *multipart.FileHeader
+			if fh := files[field.Name]; len(fh) > 0 {
+				file = fh[0]
+				clean[field.Name] = file.Filename
+			}
+			if msg := checkFile(field, file); msg != "" {
+				problems[field.Name] = msg
+			}
+			continue
+		}
+
+		v := strings.TrimSpace(values.Get(field.Name))
+		clean[field.Name] = v
+
+		switch {
+		case v == "":
+			if field.Required {
+				problems[field.Name] = field.Label + " is required."
+			}
+		case utf8.RuneCountInString(v) > field.Limit():
+			problems[field.Name] = fmt.Sprintf("%s must be at most %d characters.", field.Label, field.Limit())
+		case field.InputType() == TypeEmail && !validEmail(v):
+			problems[field.Name] = field.Label + " must be a valid email address."
+		case field.InputType() == TypePhone && !validPhone(v):
+			problems[field.Name] = field.Label + " must be a valid phone number."
+		case field.InputType() == TypeSelect && !slices.Contains(field.Options, v):
+			problems[field.Name] = field.Label + " must be one of the listed options."
+		case field.InputType() == TypeCheckbox && v != Checked:
+			problems[field.Name] = field.Label + " has an unexpected value."
+		}
+	}
+	if f.Consent != nil && values.Get(ConsentField) != Checked {
+		problems[ConsentField] = "Please confirm that you agree to the privacy notice."
+	}
+	return clean, problems
+}
+
+// ProblemList orders the messages from Validate like the form's fields, with
+// the consent last.
+func (f Form) ProblemList(problems map[string]string) []string {
+	var list []string
+	for _, field := range f.Fields {
+		if msg, ok := problems[field.Name]; ok {
+			list = append(list, msg)
+		}
+	}
+	if msg, ok := problems[ConsentField]; ok {
+		list = append(list, msg)
+	}
+	return list
+}
+
+// checkFile validates an upload for a file field; file is nil when none was
+// sent.
+func checkFile(field Field, file *multipart.FileHeader) string {
+	switch {
+	case file == nil || file.Size == 0:
+		if field.Required {
+			return field.Label + " is required."
+		}
+	case !slices.Contains(field.AcceptList(), strings.ToLower(filepath.Ext(file.Filename))):
+		return fmt.Sprintf("%s must be one of: %s.", field.Label, strings.Join(field.AcceptList(), ", "))
+	case file.Size > field.MaxBytes():
+		return fmt.Sprintf("%s must be at most %s.", field.Label, field.SizeLimit())
+	}
+	return ""
+}
+
+// validPhone accepts numbers like "021 123 4567" or "+27 (82) 123-4567".
+func validPhone(s string) bool {
+	digits := 0
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case strings.ContainsRune("+ ()-", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s, ".")
+}
+
+// Manifest is the set of forms on the site, keyed by ID.
+type Manifest map[string]Form
+
+// WriteManifest saves forms to path as JSON.
+func WriteManifest(path string, forms Manifest) error {
+	raw, err := json.MarshalIndent(forms, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0644)
+}
+
+// LoadManifest reads a manifest written by WriteManifest.
+func LoadManifest(path string) (Manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var forms Manifest
+	if err := json.Unmarshal(raw, &forms); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return forms, nil
+}
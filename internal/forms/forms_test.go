@@ -0,0 +1,258 @@
+package forms
+
+import (
+	"maps"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var contactForm = Form{
+	ID:      "contact",
+	Page:    "/contact/",
+	Success: "/contact/thank-you/",
+	Fields: []Field{
+		{Name: "name", Label: "Name", Required: true},
+		{Name: "email", Label: "Email", Type: TypeEmail, Required: true},
+		{Name: "phone", Label: "Phone", Type: TypePhone},
+		{Name: "message", Label: "Message", Type: TypeTextarea, MaxLength: 20},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	valid := url.Values{"name": {"Thandi Nkosi"}, "email": {"thandi@example.co.za"}}
+	tests := []struct {
+		name   string
+		values url.Values
+		want   map[string]string // field -> problem
+	}{
+		{"valid", valid, map[string]string{}},
+		{"missing required", url.Values{"name": {"  "}}, map[string]string{
+			"name":  "Name is required.",
+			"email": "Email is required.",
+		}},
+		{"bad email", url.Values{"name": {"Thandi"}, "email": {"thandi@"}}, map[string]string{
+			"email": "Email must be a valid email address.",
+		}},
+		{"email without a domain dot", url.Values{"name": {"Thandi"}, "email": {"thandi@localhost"}}, map[string]string{
+			"email": "Email must be a valid email address.",
+		}},
+		{"email with a display name", url.Values{"name": {"Thandi"}, "email": {"Thandi <thandi@example.co.za>"}}, map[string]string{
+			"email": "Email must be a valid email address.",
+		}},
+		{"phone formats", url.Values{"name": {"Thandi"}, "email": {"thandi@example.co.za"}, "phone": {"+27 (82) 123-4567"}}, map[string]string{}},
+		{"phone too short", url.Values{"name": {"Thandi"}, "email": {"thandi@example.co.za"}, "phone": {"123 456"}}, map[string]string{
+			"phone": "Phone must be a valid phone number.",
+		}},
+		{"phone with letters", url.Values{"name": {"Thandi"}, "email": {"thandi@example.co.za"}, "phone": {"082 CALL NOW"}}, map[string]string{
+			"phone": "Phone must be a valid phone number.",
+		}},
+		{"over the limit", url.Values{"name": {"Thandi"}, "email": {"thandi@example.co.za"}, "message": {strings.Repeat("é", 21)}}, map[string]string{
+			"message": "Message must be at most 20 characters.",
+		}},
+		{"at the limit in runes", url.Values{"name": {"Thandi"}, "email": {"thandi@example.co.za"}, "message": {strings.Repeat("é", 20)}}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := contactForm.Validate(tt.values, nil)
+			if !maps.Equal(problems, tt.want) {
+				t.Errorf("problems = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCleanValues(t *testing.T) {
+	clean, _ := contactForm.Validate(url.Values{
+		"name":    {"  Thandi Nkosi \n"},
+		"email":   {"thandi@example.co.za"},
+		"unknown": {"dropped"},
+	}, nil)
+	want := map[string]string{"name": "Thandi Nkosi", "email": "thandi@example.co.za", "phone": "", "message": ""}
+	if !maps.Equal(clean, want) {
+		t.Errorf("clean = %q, want %q", clean, want)
+	}
+}
+
+func TestProblemList(t *testing.T) {
+	_, problems := contactForm.Validate(url.Values{"phone": {"x"}}, nil)
+	got := strings.Join(contactForm.ProblemList(problems), " ")
+	if want := "Name is required. Email is required. Phone must be a valid phone number."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		form Form
+		want string // substring of the error, "" for none
+	}{
+		{"valid", contactForm, ""},
+		{"no id", Form{}, "form has no id"},
+		{"unnamed field", Form{ID: "f", Fields: []Field{{Label: "Name"}}}, `field "Name" has no name`},
+		{"duplicate field", Form{ID: "f", Fields: []Field{{Name: "name"}, {Name: "name"}}}, `duplicate field "name"`},
+		{"reserved honeypot", Form{ID: "f", Fields: []Field{{Name: HoneypotField}}}, "is reserved"},
+		{"reserved token", Form{ID: "f", Fields: []Field{{Name: TokenField}}}, "is reserved"},
+		{"unknown type", Form{ID: "f", Fields: []Field{{Name: "age", Type: "number"}}}, `unknown type "number"`},
+		{"bad notify address", Form{ID: "f", Notify: []string{"leads"}}, `notify address "leads"`},
+		{"unknown kind", Form{ID: "f", Kind: "poll"}, `unknown kind "poll"`},
+		{"review without subject", Form{ID: "f", Kind: KindReview}, "needs a subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.form.Check()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/forms.json"
+	if err := WriteManifest(path, Manifest{"contact": contactForm}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["contact"].Fields[1].Type != TypeEmail || got["contact"].Success != "/contact/thank-you/" {
+		t.Errorf("loaded %+v", got["contact"])
+	}
+}
+
+func TestValidateConsent(t *testing.T) {
+	form := Form{ID: "contact", Consent: &Consent{Version: "2026-01", Notice: "/privacy/"}}
+	for value, want := range map[string]bool{Checked: false, "": true, "on": true} {
+		_, problems := form.Validate(url.Values{ConsentField: {value}}, nil)
+		if _, got := problems[ConsentField]; got != want {
+			t.Errorf("consent %q: problem reported %v, want %v", value, got, want)
+		}
+	}
+	if _, problems := (Form{ID: "contact"}).Validate(url.Values{}, nil); len(problems) != 0 {
+		t.Errorf("form without consent reported %v", problems)
+	}
+}
+
+func TestCheckConsent(t *testing.T) {
+	for _, tt := range []struct {
+		form Form
+		ok   bool
+	}{
+		{Form{ID: "f", Consent: &Consent{Version: "2026-01"}}, true},
+		{Form{ID: "f", Consent: &Consent{}}, false},
+		{Form{ID: "f", Fields: []Field{{Name: ConsentField}}}, false},
+	} {
+		if err := tt.form.Check(); (err == nil) != tt.ok {
+			t.Errorf("%+v: Check() = %v", tt.form, err)
+		}
+	}
+}
+
+func TestValidateChoices(t *testing.T) {
+	form := Form{ID: "vat", Fields: []Field{
+		{Name: "turnover", Label: "Turnover", Type: TypeSelect, Required: true, Options: []string{"Under R1 million", "R1 million or more"}},
+		{Name: "client", Label: "I am a client", Type: TypeCheckbox},
+	}}
+	tests := []struct {
+		name   string
+		values url.Values
+		want   map[string]string
+	}{
+		{"listed option", url.Values{"turnover": {"Under R1 million"}, "client": {Checked}}, map[string]string{}},
+		{"unticked checkbox", url.Values{"turnover": {"Under R1 million"}}, map[string]string{}},
+		{"unlisted option", url.Values{"turnover": {"R5 million"}}, map[string]string{
+			"turnover": "Turnover must be one of the listed options.",
+		}},
+		{"no option", url.Values{}, map[string]string{
+			"turnover": "Turnover is required.",
+		}},
+		{"forged checkbox", url.Values{"turnover": {"Under R1 million"}, "client": {"on"}}, map[string]string{
+			"client": "I am a client has an unexpected value.",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := form.Validate(tt.values, nil)
+			if !maps.Equal(problems, tt.want) {
+				t.Errorf("problems = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	photo := Field{Name: "photo", Label: "Photo", Type: TypeFile, Accept: []string{".jpg", ".png"}, MaxMB: 2}
+	document := Field{Name: "document", Label: "Document", Type: TypeFile, Required: true}
+	tests := []struct {
+		name  string
+		field Field
+		file  *multipart.FileHeader
+		want  string
+	}{
+		{"optional and missing", photo, nil, ""},
+		{"required and missing", document, nil, "Document is required."},
+		{"required and empty", document, &multipart.FileHeader{Filename: "id.pdf"}, "Document is required."},
+		{"accepted", photo, &multipart.FileHeader{Filename: "me.jpg", Size: 1 << 20}, ""},
+		{"extension case ignored", photo, &multipart.FileHeader{Filename: "ME.PNG", Size: 1 << 20}, ""},
+		{"wrong type", photo, &multipart.FileHeader{Filename: "me.pdf", Size: 1 << 20}, "Photo must be one of: .jpg, .png."},
+		{"no extension", photo, &multipart.FileHeader{Filename: "me", Size: 1 << 20}, "Photo must be one of: .jpg, .png."},
+		{"too large", photo, &multipart.FileHeader{Filename: "me.jpg", Size: 2<<20 + 1}, "Photo must be at most 2 MB."},
+		{"default types", document, &multipart.FileHeader{Filename: "irp5.jpeg", Size: 1 << 20}, ""},
+		{"default size", document, &multipart.FileHeader{Filename: "statement.pdf", Size: 6 << 20}, "Document must be at most 5 MB."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkFile(tt.field, tt.file); got != tt.want {
+				t.Errorf("checkFile = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	form := Form{ID: "vat", Fields: []Field{
+		{Name: "name", Label: "Name"},
+		{Name: "document", Label: "Document", Type: TypeFile, Required: true},
+		{Name: "photo", Label: "Photo", Type: TypeFile, MaxMB: 1},
+	}}
+	if !form.HasFiles() || form.MaxUploadBytes() != 6<<20 {
+		t.Errorf("HasFiles = %v, MaxUploadBytes = %d", form.HasFiles(), form.MaxUploadBytes())
+	}
+	clean, problems := form.Validate(url.Values{"name": {"Thandi"}, "document": {"forged.pdf"}}, map[string][]*multipart.FileHeader{
+		"photo": {{Filename: "me.png", Size: 100}},
+	})
+	if want := map[string]string{"name": "Thandi", "photo": "me.png"}; !maps.Equal(clean, want) {
+		t.Errorf("clean = %q, want %q", clean, want)
+	}
+	if want := map[string]string{"document": "Document is required."}; !maps.Equal(problems, want) {
+		t.Errorf("problems = %q, want %q", problems, want)
+	}
+}
+
+func TestCheckFieldTypes(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{Field{Name: "turnover", Type: TypeSelect, Options: []string{"Low"}}, ""},
+		{Field{Name: "turnover", Type: TypeSelect}, "select turnover has no options"},
+		{Field{Name: "photo", Type: TypeFile, Accept: []string{".jpg"}}, ""},
+		{Field{Name: "photo", Type: TypeFile, Accept: []string{"jpg"}}, `accepts "jpg"`},
+	}
+	for _, tt := range tests {
+		err := Form{ID: "f", Fields: []Field{tt.field}}.Check()
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%+v: unexpected error: %v", tt.field, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%+v: error = %v, want one containing %q", tt.field, err, tt.want)
+		}
+	}
+}
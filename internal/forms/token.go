@@ -0,0 +1,74 @@
+package forms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reserved field names added to every form for spam protection.
+const (
+	// HoneypotField is hidden from people; bots that fill it are rejected.
+	HoneypotField = "homepage"
+	// TokenField carries a signed timestamp, see SignToken.
+	TokenField = "_token"
+)
+
+// SecretEnv names the environment variable holding the key the form server
+// signs form tokens with. Tokens are only issued by the form server, when a
+// page fetches one; the builder never signs them into pages, where they
+// would outlive any fill-time check.
+const SecretEnv = "FORM_SECRET"
+
+// Secret returns the token signing key from the environment, or nil.
+func Secret() []byte {
+	if s := os.Getenv(SecretEnv); s != "" {
+		return []byte(s)
+	}
+	return nil
+}
+
+var (
+	ErrBadToken     = errors.New("invalid form token")
+	ErrTokenExpired = errors.New("form token expired")
+	ErrTooFast      = errors.New("form submitted too quickly")
+)
+
+// SignToken returns a token binding formID to the time the form was issued,
+// as "<unix seconds>.<hmac>".
+func SignToken(secret []byte, formID string, issued time.Time) string {
+	ts := strconv.FormatInt(issued.Unix(), 10)
+	return ts + "." + tokenMAC(secret, formID, ts)
+}
+
+// CheckToken verifies token for formID and that at least minFill has passed
+// since it was issued. A maxAge of zero means tokens never expire.
+func CheckToken(secret []byte, formID, token string, now time.Time, minFill, maxAge time.Duration) error {
+	ts, mac, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(tokenMAC(secret, formID, ts))) {
+		return ErrBadToken
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrBadToken
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age < minFill {
+		return ErrTooFast
+	}
+	if maxAge > 0 && age > maxAge {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+func tokenMAC(secret []byte, formID, ts string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(formID + "|" + ts))
+	return hex.EncodeToString(h.Sum(nil))
+}
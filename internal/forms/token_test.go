@@ -0,0 +1,45 @@
+package forms
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckToken(t *testing.T) {
+	secret := []byte("s3cret")
+	issued := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	token := SignToken(secret, "contact", issued)
+	ts, mac, _ := strings.Cut(token, ".")
+	later := SignToken(secret, "contact", issued.Add(time.Minute))
+	laterTS, _, _ := strings.Cut(later, ".")
+
+	tests := []struct {
+		name   string
+		secret []byte
+		form   string
+		token  string
+		after  time.Duration // since issued
+		maxAge time.Duration
+		want   error
+	}{
+		{"valid", secret, "contact", token, time.Minute, 2 * time.Hour, nil},
+		{"too fast", secret, "contact", token, 2 * time.Second, 2 * time.Hour, ErrTooFast},
+		{"expired", secret, "contact", token, 3 * time.Hour, 2 * time.Hour, ErrTokenExpired},
+		{"no expiry", secret, "contact", token, 30 * 24 * time.Hour, 0, nil},
+		{"other form", secret, "vat-registration", token, time.Minute, 2 * time.Hour, ErrBadToken},
+		{"other secret", []byte("other"), "contact", token, time.Minute, 2 * time.Hour, ErrBadToken},
+		{"mac tampered", secret, "contact", ts + "." + mac[:len(mac)-1] + "0", time.Minute, 2 * time.Hour, ErrBadToken},
+		{"timestamp swapped", secret, "contact", laterTS + "." + mac, 2 * time.Minute, 2 * time.Hour, ErrBadToken},
+		{"no mac", secret, "contact", ts, time.Minute, 2 * time.Hour, ErrBadToken},
+		{"empty", secret, "contact", "", time.Minute, 2 * time.Hour, ErrBadToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckToken(tt.secret, tt.form, tt.token, issued.Add(tt.after), 3*time.Second, tt.maxAge)
+			if err != tt.want {
+				t.Errorf("CheckToken = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Package reviews holds the published reviews of practitioners. Visitors post
+// reviews through the form server, which queues them for moderation; each
+// approved review is added to data/reviews/<practitioner slug>.yaml, and the
+// builder shows them on the practitioner's profile.
+package reviews
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"website/internal/forms"
+)
+
+// Dir is where published reviews live, relative to the repository root.
+const Dir = "data/reviews"
+
+// Ratings run from MinRating to MaxRating stars.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is a published review of a practitioner.
+type Review struct {
+	ID       string    `yaml:"id" json:"id"` // of the submission it came from
+	Rating   int       `yaml:"rating" json:"rating"`
+	Text     string    `yaml:"text" json:"text"`
+	Name     string    `yaml:"name" json:"name"`         // as the reviewer gave it
+	Verified bool      `yaml:"verified" json:"verified"` // a moderator confirmed they are a client
+	Date     time.Time `yaml:"date" json:"date"`         // when it was submitted
+}
+
+// Check reports problems with the review.
+func (r Review) Check() error {
+	if r.ID == "" || r.Name == "" || r.Text == "" {
+		return fmt.Errorf("review %q: id, name and text are required", r.ID)
+	}
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		return fmt.Errorf("review %s: rating %d is not between %d and %d", r.ID, r.Rating, MinRating, MaxRating)
+	}
+	return nil
+}
+
+// Stars draws the rating, e.g. "★★★★☆".
+func (r Review) Stars() string {
+	return strings.Repeat("★", r.Rating) + strings.Repeat("☆", MaxRating-r.Rating)
+}
+
+// Summary is the average rating of a list of reviews.
+type Summary struct {
+	Count   int
+	Average float64 // rounded to one decimal
+}
+
+// Summarize averages the ratings of list.
+func Summarize(list []Review) Summary {
+	if len(list) == 0 {
+		return Summary{}
+	}
+	total := 0
+	for _, r := range list {
+		total += r.Rating
+	}
+	return Summary{Count: len(list), Average: math.Round(float64(total)/float64(len(list))*10) / 10}
+}
+
+// String reads like "4.5".
+func (s Summary) String() string {
+	return strconv.FormatFloat(s.Average, 'f', 1, 64)
+}
+
+// Stars draws the average rounded to whole stars.
+func (s Summary) Stars() string {
+	return Review{Rating: int(math.Round(s.Average))}.Stars()
+}
+
+// The fields of the review form. The form server queues posts for
+// moderation and turns an approved one into a Review with FromSubmission.
+const (
+	fieldRating = "rating"
+	fieldText   = "review"
+	fieldName   = "name"
+	fieldEmail  = "email"
+	fieldClient = "client"
+)
+
+// ratingOptions are the choices of the rating select, best first. Each
+// starts with its number of stars.
+var ratingOptions = []string{"5 - Excellent", "4 - Good", "3 - Average", "2 - Poor", "1 - Very poor"}
+
+// FormFields are the fields of the form reviewing practitioner, named by
+// their first name.
+func FormFields(practitioner string) []forms.Field {
+	return []forms.Field{
+		{Name: fieldRating, Label: "Rating", Type: forms.TypeSelect, Required: true, Options: ratingOptions},
+		{Name: fieldText, Label: "Your review", Type: forms.TypeTextarea, Required: true, MaxLength: 2000},
+		{Name: fieldName, Label: "Your name", Required: true, Help: "Shown with your review. Your first name and an initial is fine."},
+		{Name: fieldEmail, Label: "Email", Type: forms.TypeEmail, Required: true, Help: "Never shown. We may contact you to confirm your review."},
+		{Name: fieldClient, Label: fmt.Sprintf("I have used %s's services", practitioner), Type: forms.TypeCheckbox},
+	}
+}
+
+// FromSubmission turns the values of a review form into a review. Verified
+// is left for the moderator to set.
+func FromSubmission(id string, values map[string]string, received time.Time) (Review, error) {
+	rating, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(values[fieldRating], " ", 2)[0]))
+	if err != nil {
+		return Review{}, fmt.Errorf("review %s: rating %q is not a number", id, values[fieldRating])
+	}
+	r := Review{
+		ID:     id,
+		Rating: rating,
+		Text:   strings.TrimSpace(values[fieldText]),
+		Name:   strings.TrimSpace(values[fieldName]),
+		Date:   received,
+	}
+	return r, r.Check()
+}
+
+// ClaimsClient reports whether the reviewer ticked that they are a client.
+func ClaimsClient(values map[string]string) bool {
+	return values[fieldClient] == forms.Checked
+}
+
+// Load reads every practitioner's published reviews from dir, newest first,
+// by practitioner slug. A missing directory has no reviews. Files starting
+// with "_" are skipped, so examples can live beside the real ones.
+func Load(dir string) (map[string][]Review, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string][]Review)
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(path), "_") {
+			continue
+		}
+		list, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		all[strings.TrimSuffix(filepath.Base(path), ".yaml")] = list
+	}
+	return all, nil
+}
+
+func loadFile(path string) ([]Review, error) {
+	var list []Review
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range list {
+		if err := r.Check(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Date.After(list[j].Date) })
+	return list, nil
+}
+
+// Publish adds r to the reviews of the practitioner with slug.
+func Publish(dir, slug string, r Review) error {
+	if err := r.Check(); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, slug+".yaml")
+	list, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, prev := range list {
+		if prev.ID == r.ID {
+			return fmt.Errorf("review %s is already published in %s", r.ID, path)
+		}
+	}
+	return writeFile(path, append(list, r))
+}
+
+// Unpublish removes the review with id from whichever practitioner has it,
+// and reports whether it was found. A file left empty is deleted.
+func Unpublish(dir, id string) (bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return false, err
+	}
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(path), "_") {
+			continue
+		}
+		list, err := loadFile(path)
+		if err != nil {
+			return false, err
+		}
+		for i, r := range list {
+			if r.ID != id {
+				continue
+			}
+			if len(list) == 1 {
+				return true, os.Remove(path)
+			}
+			return true, writeFile(path, append(list[:i], list[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// writeFile saves list newest first, through a temporary file so readers
+// never see half a file.
+func writeFile(path string, list []Review) error {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Date.After(list[j].Date) })
+	var buf bytes.Buffer
+	buf.WriteString("# Published reviews, newest first. Added by `formserver reviews approve`.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(list); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".reviews-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
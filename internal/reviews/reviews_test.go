@@ -0,0 +1,145 @@
+package reviews
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func review(id string, rating int, daysAgo int) Review {
+	return Review{ID: id, Rating: rating, Text: "Helpful.", Name: "Lerato", Date: day.AddDate(0, 0, -daysAgo)}
+}
+
+// ids lists the IDs of the reviews of slug in dir, as Load returns them.
+func ids(t *testing.T, dir, slug string) string {
+	t.Helper()
+	all, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []string
+	for _, r := range all[slug] {
+		list = append(list, r.ID)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestPublishAndUnpublish(t *testing.T) {
+	dir := t.TempDir()
+	for _, r := range []Review{review("old", 4, 10), review("new", 5, 1), review("mid", 3, 5)} {
+		if err := Publish(dir, "thandi-mokoena", r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Publish(dir, "sipho-dlamini", review("other", 2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(t, dir, "thandi-mokoena"), "new mid old"; got != want {
+		t.Errorf("published %q, want newest first %q", got, want)
+	}
+	if err := Publish(dir, "thandi-mokoena", review("mid", 3, 5)); err == nil {
+		t.Error("published the same review twice")
+	}
+	if err := Publish(dir, "thandi-mokoena", review("bad", 6, 0)); err == nil {
+		t.Error("published a rating of 6")
+	}
+
+	tests := []struct {
+		id        string
+		found     bool
+		thandi    string
+		sipho     string
+		siphoFile bool
+	}{
+		{"mid", true, "new old", "other", true},
+		{"mid", false, "new old", "other", true},
+		{"unknown", false, "new old", "other", true},
+		{"other", true, "new old", "", false}, // the emptied file goes
+	}
+	for _, tt := range tests {
+		found, err := Unpublish(dir, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != tt.found {
+			t.Errorf("Unpublish(%s) found %v, want %v", tt.id, found, tt.found)
+		}
+		if got := ids(t, dir, "thandi-mokoena"); got != tt.thandi {
+			t.Errorf("after Unpublish(%s): thandi-mokoena has %q, want %q", tt.id, got, tt.thandi)
+		}
+		if got := ids(t, dir, "sipho-dlamini"); got != tt.sipho {
+			t.Errorf("after Unpublish(%s): sipho-dlamini has %q, want %q", tt.id, got, tt.sipho)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "sipho-dlamini.yaml")); (err == nil) != tt.siphoFile {
+			t.Errorf("after Unpublish(%s): sipho-dlamini.yaml exists: %v", tt.id, err == nil)
+		}
+	}
+}
+
+func TestLoadSkipsExamples(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "_example.yaml"), []byte("- id: x\n  rating: 9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	all, err := Load(dir)
+	if err != nil || len(all) != 0 {
+		t.Errorf("Load = %v, %v; want nothing", all, err)
+	}
+	if all, err := Load(filepath.Join(dir, "missing")); err != nil || len(all) != 0 {
+		t.Errorf("missing directory: %v, %v", all, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		ratings []int
+		count   int
+		average string
+		stars   string
+	}{
+		{nil, 0, "0.0", "☆☆☆☆☆"},
+		{[]int{5}, 1, "5.0", "★★★★★"},
+		{[]int{5, 4}, 2, "4.5", "★★★★★"},
+		{[]int{4, 4, 5}, 3, "4.3", "★★★★☆"},
+		{[]int{1, 2}, 2, "1.5", "★★☆☆☆"},
+	}
+	for _, tt := range tests {
+		var list []Review
+		for _, rating := range tt.ratings {
+			list = append(list, Review{Rating: rating})
+		}
+		s := Summarize(list)
+		if s.Count != tt.count || s.String() != tt.average || s.Stars() != tt.stars {
+			t.Errorf("Summarize(%v) = %d, %s, %s; want %d, %s, %s", tt.ratings, s.Count, s, s.Stars(), tt.count, tt.average, tt.stars)
+		}
+	}
+}
+
+func TestFromSubmission(t *testing.T) {
+	tests := []struct {
+		rating string
+		want   int
+		ok     bool
+	}{
+		{"5 - Excellent", 5, true},
+		{"1 - Very poor", 1, true},
+		{"4", 4, true},
+		{"Excellent", 0, false},
+		{"7 - Too good", 7, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		r, err := FromSubmission("abc", map[string]string{fieldRating: tt.rating, fieldText: " Quick. ", fieldName: "Thandi M"}, day)
+		if (err == nil) != tt.ok {
+			t.Errorf("rating %q: error %v", tt.rating, err)
+			continue
+		}
+		if tt.ok && (r.Rating != tt.want || r.Text != "Quick." || r.Date != day || r.Verified) {
+			t.Errorf("rating %q: review %+v", tt.rating, r)
+		}
+	}
+}